@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+)
+
+// The magic numbers and the bytes needed to recognise the compressed streams
+var (
+	gzipMagic       = []byte{0x1f, 0x8b, 0x08}
+	bzip2Magic      = []byte("BZh")
+	bzip2BlockMagic = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2EndMagic   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+const (
+	compressionHeaderSize = 10
+	decompressBufferSize  = 64 * 1024
+)
+
+// decompressed returns the decompressed content of r when it is a gzip, bzip2
+// or zlib stream, anything else is returned as is. The gzip files with
+// several members are read to the end like zcat does.
+func decompressed(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, decompressBufferSize)
+	header, _ := br.Peek(compressionHeaderSize)
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return gzip.NewReader(br)
+	case isBzip2(header):
+		return bzip2.NewReader(br), nil
+	case isZlib(header):
+		// The zlib header is only two bytes and plain text can start with
+		// them, so the data at hand must also decompress
+		buffered, _ := br.Peek(br.Buffered())
+		if validZlib(buffered) {
+			return zlib.NewReader(br)
+		}
+	}
+	return br, nil
+}
+
+// isBzip2 checks the stream header and the magic of the first block, or of the
+// end of the stream for an empty one
+func isBzip2(header []byte) bool {
+	if len(header) < compressionHeaderSize || !bytes.HasPrefix(header, bzip2Magic) {
+		return false
+	}
+	if header[3] < '1' || header[3] > '9' {
+		return false
+	}
+	return bytes.Equal(header[4:], bzip2BlockMagic) || bytes.Equal(header[4:], bzip2EndMagic)
+}
+
+// isZlib checks the deflate method and window size, no preset dictionary and
+// the header checksum of RFC 1950
+func isZlib(header []byte) bool {
+	if len(header) < 2 {
+		return false
+	}
+	cmf, flg := header[0], header[1]
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && flg&0x20 == 0 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
+
+// validZlib reports whether the start of a zlib stream decompresses without
+// errors, running out of data is fine as only the start is at hand
+func validZlib(data []byte) bool {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	_, err = io.Copy(io.Discard, zr)
+	return err == nil || err == io.ErrUnexpectedEOF
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "gzip with multiple members",
+			args:     []string{"--decompress", "testdata/multi.gz"},
+			expected: "first member\nsecond member\n",
+		},
+		{
+			name:     "bzip2 formatted",
+			args:     []string{"--decompress", "-nT", "testdata/lines.bz2"},
+			expected: "     1\tbzip2^Iline\n     2\t\n     3\tend\n",
+		},
+		{
+			name:     "zlib shown nonprinting",
+			args:     []string{"--decompress", "-v", "testdata/lines.zlib"},
+			expected: "zlib^Aline\n",
+		},
+		{
+			name:     "plain text with a zlib like header",
+			args:     []string{"--decompress", "testdata/zlibheader.txt"},
+			expected: "Hj plain text\n",
+		},
+		{
+			name:     "numbering continues across compressed and plain files",
+			args:     []string{"--decompress", "-n", "testdata/multi.gz", "testdata/zlibheader.txt"},
+			expected: "     1\tfirst member\n     2\tsecond member\n     3\tHj plain text\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecompressWithoutFlagIsRaw(t *testing.T) {
+	expected, err := os.ReadFile("testdata/multi.gz")
+	if err != nil {
+		t.Fatalf("Unable to read testdata, err: %s", err)
+	}
+	if actual := runCat(t, "testdata/multi.gz"); actual != string(expected) {
+		t.Errorf("cat testdata/multi.gz changed the compressed data")
+	}
+}
+
+func TestDecompressTruncated(t *testing.T) {
+	_, stderr, err := runCatErr(t, "--decompress", "testdata/truncated.gz")
+	if err == nil {
+		t.Errorf("cat of a truncated gzip file should fail")
+	}
+	if expected := "cat: testdata/truncated.gz: unexpected EOF\n"; stderr != expected {
+		t.Errorf("cat stderr = %q, want %q", stderr, expected)
+	}
+}
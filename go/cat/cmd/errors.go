@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unicode"
+)
+
+type CatError string
+
+// interruptedStatus is the exit status of the --follow interrupted, the one of
+// a shell for a command killed by SIGINT
+const interruptedStatus = 128 + int(syscall.SIGINT)
+
+func (e CatError) Error() string {
+	return string(e)
+}
+
+var (
+	inputIsOutputError = CatError("input file is output file")
+	operandFailedError = CatError("one or more operands failed")
+	// interruptedError ends the --follow stopped by a signal
+	interruptedError = CatError("interrupted")
+	// bidiControlsFoundError fails the operand with --check
+	bidiControlsFoundError = CatError("bidirectional control characters")
+	// outputsFailedError stops cat once the standard output and all the --tee
+	// files failed, each one is reported as it fails
+	outputsFailedError = CatError("all the outputs failed")
+
+	// The notices of --follow, reported like the errors as tail does
+	fileTruncatedNotice = CatError("file truncated")
+	fileReplacedNotice  = CatError("file replaced, following the new file")
+)
+
+// writeError is an error of writing to the standard output, which stops cat
+// and is reported as a write error instead of an error of the operand
+type writeError struct {
+	err error
+}
+
+func (e writeError) Error() string {
+	return e.err.Error()
+}
+
+func (e writeError) Unwrap() error {
+	return e.err
+}
+
+// errorText returns the error in the words of GNU cat, the system errors are
+// shown like strerror without the operation and path added by Go
+func errorText(err error) string {
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		text := []rune(errno.Error())
+		text[0] = unicode.ToUpper(text[0])
+		return string(text)
+	}
+	return err.Error()
+}
+
+// reportError writes the error for the operand as "cat: NAME: error"
+func reportError(w io.Writer, name string, err error) {
+	fmt.Fprintf(w, "%s: %s: %s\n", ProgramName, name, errorText(err))
+}
+
+// sameFile reports whether the input is the output with data left to read,
+// cat would then read its own output for ever. Only a regular output file can
+// be read back, like GNU cat an input read to its end is not reported.
+func sameFile(input *os.File, outInfo os.FileInfo) bool {
+	if outInfo == nil || !outInfo.Mode().IsRegular() {
+		return false
+	}
+	inInfo, err := input.Stat()
+	if err != nil || !os.SameFile(inInfo, outInfo) {
+		return false
+	}
+	pos, err := input.Seek(0, io.SeekCurrent)
+	return err == nil && pos < inInfo.Size()
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// followInterval is how long the follower waits for new data at the end of
+// the file
+var followInterval = 250 * time.Millisecond
+
+// follower reads a regular file like tail -F, at its end it waits for the data
+// appended to it. A truncated file is read again from its start and a file
+// replaced by the rotation, renamed and created again, is followed under its
+// name.
+type follower struct {
+	name string
+	f    *os.File
+	// opened is set once f is opened by the follower after a rotation
+	opened bool
+	// flush writes the output held back before waiting
+	flush  func() error
+	errOut io.Writer
+	// done stops the following, the data read so far is kept
+	done <-chan struct{}
+	// stopped is set once the following is stopped by done
+	stopped bool
+}
+
+func newFollower(name string, f *os.File, flush func() error, done <-chan struct{}) *follower {
+	return &follower{name: name, f: f, flush: flush, errOut: os.Stderr, done: done}
+}
+
+func (fl *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := fl.f.Read(p)
+		if n > 0 || !errors.Is(err, io.EOF) {
+			return n, err
+		}
+
+		if err := fl.flush(); err != nil {
+			return 0, err
+		}
+		reopened, err := fl.reopen()
+		if err != nil {
+			return 0, err
+		}
+		if reopened {
+			continue
+		}
+		select {
+		case <-fl.done:
+			fl.stopped = true
+			return 0, io.EOF
+		case <-time.After(followInterval):
+		}
+	}
+}
+
+// reopen checks the file at its end, it reports whether the reading goes on
+// from the rest of a replaced file, from the new file or from the start of a
+// truncated file. The standard input has no name to open again.
+func (fl *follower) reopen() (bool, error) {
+	// The name is looked up first, so that the size of the old file holds
+	// everything written to it before the rename
+	var named os.FileInfo
+	if fl.name != "-" {
+		// Between the rename and the creation the name is missing for a while
+		named, _ = os.Stat(fl.name)
+	}
+	info, err := fl.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	pos, err := fl.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	if named != nil && !os.SameFile(info, named) {
+		// The rest of the old file is read before the new one
+		if info.Size() > pos {
+			return true, nil
+		}
+		f, err := os.Open(fl.name)
+		if err != nil {
+			return false, err
+		}
+		if fl.opened {
+			fl.f.Close()
+		}
+		fl.f, fl.opened = f, true
+		reportError(fl.errOut, fl.name, fileReplacedNotice)
+		return true, nil
+	}
+
+	if info.Size() >= pos {
+		return false, nil
+	}
+	if _, err := fl.f.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	reportError(fl.errOut, fl.name, fileTruncatedNotice)
+	return true, nil
+}
+
+// Close closes the file opened after a rotation, the first one belongs to the
+// caller
+func (fl *follower) Close() error {
+	if !fl.opened {
+		return nil
+	}
+	return fl.f.Close()
+}
+
+// following reports whether the input is followed, only the data appended to
+// a regular file can be waited for
+func following(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollower(t *testing.T) {
+	followInterval = time.Millisecond
+	name := filepath.Join(t.TempDir(), "app.log")
+	rotated := name + ".1"
+	writeFile := func(path string, flag int, data string) {
+		t.Helper()
+		f, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0o600)
+		if err != nil {
+			t.Fatalf("Unable to open %s, err: %s", path, err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(data); err != nil {
+			t.Fatalf("Unable to write %s, err: %s", path, err)
+		}
+	}
+
+	writeFile(name, os.O_TRUNC, "first\n")
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("Unable to open %s, err: %s", name, err)
+	}
+	defer f.Close()
+	done := make(chan struct{})
+	var notices bytes.Buffer
+	// atEOF changes the file once the follower reached its end
+	var atEOF func()
+	fl := newFollower(name, f, func() error {
+		if atEOF != nil {
+			atEOF()
+			atEOF = nil
+		}
+		return nil
+	}, done)
+	fl.errOut = &notices
+	defer fl.Close()
+
+	steps := []struct {
+		name     string
+		change   func()
+		expected string
+	}{
+		{name: "start", change: func() {}, expected: "first\n"},
+		{name: "appended", change: func() { writeFile(name, os.O_APPEND, "app") }, expected: "app"},
+		{
+			name: "appended after the rename",
+			change: func() {
+				if err := os.Rename(name, rotated); err != nil {
+					t.Fatalf("Unable to rename %s, err: %s", name, err)
+				}
+				writeFile(rotated, os.O_APPEND, "ended\n")
+			},
+			expected: "ended\n",
+		},
+		{name: "created again", change: func() { writeFile(name, os.O_EXCL, "new\n") }, expected: "new\n"},
+		{
+			name: "appended before the rename",
+			change: func() {
+				atEOF = func() {
+					writeFile(name, os.O_APPEND, "last\n")
+					if err := os.Rename(name, rotated+".2"); err != nil {
+						t.Fatalf("Unable to rename %s, err: %s", name, err)
+					}
+					writeFile(name, os.O_EXCL, "newer\n")
+				}
+			},
+			expected: "last\nnewer\n",
+		},
+		{name: "appended to the new file", change: func() { writeFile(name, os.O_APPEND, "longer line\n") }, expected: "longer line\n"},
+		{name: "truncated", change: func() { writeFile(name, os.O_TRUNC, "short\n") }, expected: "short\n"},
+	}
+	for _, step := range steps {
+		step.change()
+		actual := make([]byte, len(step.expected))
+		if _, err := io.ReadFull(fl, actual); err != nil || string(actual) != step.expected {
+			t.Errorf("%s: read %q, %v, want %q", step.name, actual, err, step.expected)
+		}
+	}
+
+	close(done)
+	if n, err := fl.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+		t.Errorf("read after done = %d, %v, want 0, EOF", n, err)
+	}
+	replaced := "cat: " + name + ": file replaced, following the new file\n"
+	expected := replaced + replaced + "cat: " + name + ": file truncated\n"
+	if notices.String() != expected {
+		t.Errorf("notices = %q, want %q", notices.String(), expected)
+	}
+}
+
+// TestFollowNumbering checks that the lines are numbered on across the
+// rotation of the followed file
+func TestFollowNumbering(t *testing.T) {
+	followInterval = time.Millisecond
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(name, []byte("one\ntw"), 0o600); err != nil {
+		t.Fatalf("Unable to write %s, err: %s", name, err)
+	}
+	out, err := os.Create(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("Unable to create output, err: %s", err)
+	}
+	defer out.Close()
+
+	parseArgs(t, "-n", "-f", name)
+	oldStderr := os.Stderr
+	defer func() { os.Stderr = oldStderr }()
+	os.Stderr = out
+
+	o := newOutput(out, formatOptions())
+	done := make(chan struct{})
+	o.done = done
+	result := make(chan error)
+	go func() { result <- o.cat(name, true) }()
+
+	waitForOutput(t, out.Name(), "     1\tone\n     2\ttw")
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatalf("Unable to rename %s, err: %s", name, err)
+	}
+	if err := os.WriteFile(name, []byte("o\nthree\n"), 0o600); err != nil {
+		t.Fatalf("Unable to write %s, err: %s", name, err)
+	}
+	waitForOutput(t, out.Name(), "     1\tone\n     2\ttw"+
+		"cat: "+name+": file replaced, following the new file\no\n     3\tthree\n")
+
+	close(done)
+	if err := <-result; err != nil {
+		t.Errorf("cat -f %s failed: %v", name, err)
+	}
+	if !o.interrupted {
+		t.Errorf("cat -f %s should be interrupted", name)
+	}
+}
+
+// waitForOutput waits for the output file to hold expected
+func waitForOutput(t *testing.T, path string, expected string) {
+	t.Helper()
+	var actual []byte
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		var err error
+		if actual, err = os.ReadFile(path); err != nil {
+			t.Fatalf("Unable to read output, err: %s", err)
+		}
+		if string(actual) == expected {
+			return
+		}
+	}
+	t.Fatalf("output = %q, want %q", actual, expected)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+const reverseBlockSize = 64 * 1024
+
+// reverse writes the records of r from the last to the first like tac, each
+// record ends with sep but the last one may not. A regular file is read
+// backward in blocks from its end, any other input is first copied to a
+// temporary file.
+func reverse(dst io.Writer, r io.Reader, sep []byte) error {
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			// The standard input can be partially read already
+			start, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+			return reverseFile(dst, f, start, info.Size(), sep)
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "cat-reverse-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return err
+	}
+	return reverseFile(dst, tmp, 0, size, sep)
+}
+
+// reverseFile writes the records of f between start and end from the last to
+// the first. The blocks are read backward into a buffer growing at its front
+// until the start of the last record is found, only the new bytes are searched.
+// The records are written in blocks too.
+func reverseFile(dst io.Writer, f io.ReaderAt, start, end int64, sep []byte) error {
+	var (
+		// The data read but not written is buf[off:], from pos in the file
+		buf []byte
+		off int
+		pos = end
+		// unsearched is the length of the start of the data that can hold the
+		// separator ahead of the last record
+		unsearched int
+		out        []byte
+	)
+	for {
+		data := buf[off:]
+		// The separator ending the last record is part of it
+		if i := bytes.LastIndex(data[:min(unsearched, max(len(data)-1, 0))], sep); i >= 0 {
+			out = append(out, data[i+len(sep):]...)
+			if len(out) >= reverseBlockSize {
+				if _, err := dst.Write(out); err != nil {
+					return err
+				}
+				out = out[:0]
+			}
+			buf = buf[:off+i+len(sep)]
+			unsearched = len(buf) - off
+			continue
+		}
+		if pos == start {
+			if out = append(out, data...); len(out) == 0 {
+				return nil
+			}
+			_, err := dst.Write(out)
+			return err
+		}
+
+		n := int(min(reverseBlockSize, pos-start))
+		if off < n {
+			grown := make([]byte, 2*len(data)+n)
+			off = len(grown) - len(data)
+			copy(grown[off:], data)
+			buf = grown
+		}
+		off -= n
+		pos -= int64(n)
+		// A file shrinking under the reading ends early
+		if m, err := f.ReadAt(buf[off:off+n], pos); m < n {
+			if err == nil || errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		// A separator can start in the new block and end in the old data
+		unsearched = n + len(sep) - 1
+	}
+}
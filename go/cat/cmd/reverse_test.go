@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// reverseRecords reverses the records of data split after each sep in memory
+func reverseRecords(data, sep string) string {
+	records := strings.SplitAfter(data, sep)
+	var sb strings.Builder
+	for i := len(records) - 1; i >= 0; i-- {
+		sb.WriteString(records[i])
+	}
+	return sb.String()
+}
+
+func TestReverseFile(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	// Records longer than the block size and separators across the blocks
+	long := strings.Repeat("x", reverseBlockSize+7)
+	var many strings.Builder
+	for many.Len() < 3*reverseBlockSize {
+		many.WriteString(strings.Repeat("y", rnd.Intn(40)))
+		many.WriteString("\r\n")
+	}
+
+	tests := []struct {
+		name string
+		data string
+		sep  string
+	}{
+		{name: "empty", data: "", sep: "\n"},
+		{name: "lines", data: "a\nb\nc\n", sep: "\n"},
+		{name: "no trailing separator", data: "a\nb\nc", sep: "\n"},
+		{name: "empty records", data: "\n\na\n\n", sep: "\n"},
+		{name: "multibyte separator", data: "a--b----c", sep: "--"},
+		{name: "long record", data: "a\n" + long + "\nb\n" + long, sep: "\n"},
+		{name: "many records", data: many.String(), sep: "\r\n"},
+		{name: "separator across blocks", data: strings.Repeat("z", reverseBlockSize-1) + "\r\nend", sep: "\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			r := strings.NewReader(tt.data)
+			if err := reverseFile(&out, r, 0, int64(len(tt.data)), []byte(tt.sep)); err != nil {
+				t.Fatalf("reverseFile failed, err: %s", err)
+			}
+			if expected := reverseRecords(tt.data, tt.sep); out.String() != expected {
+				t.Errorf("reverseFile of %.20q = %.40q, want %.40q", tt.data, out.String(), expected)
+			}
+		})
+	}
+}
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "without a trailing newline",
+			args:     []string{"--reverse", "testdata/noeol.txt"},
+			expected: "secondfirst\n",
+		},
+		{
+			name:     "numbered across files",
+			args:     []string{"--reverse", "-n", "testdata/noeol.txt", "testdata/noeol.txt"},
+			expected: "     1\tsecondfirst\n     2\tsecondfirst\n",
+		},
+		{
+			name:     "selected after reversing",
+			args:     []string{"--reverse", "--lines", "1", "testdata/noeol.txt"},
+			expected: "secondfirst\n",
+		},
+		{
+			name:     "custom separator",
+			args:     []string{"--reverse", "--reverse-separator", "i", "testdata/noeol.txt"},
+			expected: "rst\nsecondfi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReverseStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unable to create pipe, err: %s", err)
+	}
+	go func() {
+		_, _ = w.Write([]byte("one\ntwo\nthree\n"))
+		w.Close()
+	}()
+	old := os.Stdin
+	defer func() { os.Stdin = old }()
+	os.Stdin = r
+	defer r.Close()
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	if actual, expected := runCat(t, "--reverse", "-"), "three\ntwo\none\n"; actual != expected {
+		t.Errorf("cat --reverse - = %q, want %q", actual, expected)
+	}
+	// The temporary copy is removed
+	if matches, _ := filepath.Glob(filepath.Join(tmpDir, "cat-reverse-*")); len(matches) > 0 {
+		t.Errorf("temporary files left: %v", matches)
+	}
+}
@@ -2,14 +2,26 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 
+	"github.com/ennc0d3/coding-challenges/cat/format"
+	"github.com/ennc0d3/coding-challenges/cat/hexdump"
+	"github.com/ennc0d3/coding-challenges/cat/highlight"
+	"github.com/ennc0d3/coding-challenges/cat/transcode"
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+	"github.com/enncod3/coding-challenges/cut/rangeutil"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 const (
+	ProgramName   = "cat"
 	VersionNumber = "0.0.1"
 	Author        = "ennc0d3"
 )
@@ -25,26 +37,113 @@ var (
 	showEndsAndNonPrinting bool
 	showTabsAndNonPrinting bool
 	showAll                bool
+	decompress             bool
+	lines                  string
+	headers                bool
+	separator              string
+	ensureNewline          bool
+	showUnicode            bool
+	checkUnicode           bool
+	highlightMode          string
+	hex                    bool
+	hexWidth               int
+	hexGroup               int
+	unhex                  bool
+	fromEncoding           string
+	toEncoding             string
+	stripBOM               bool
+	onInvalid              string
+	eolName                string
+	stripANSI              bool
+	reverseLines           bool
+	reverseSeparator       string
+	followFile             bool
+	teeFiles               []string
+	appendTee              bool
+	zeroTerminated         bool
+	recordSeparator        string
+
+	lineRanges   []rangeutil.Range
+	highlighting bool
+	eol          format.EOL
+	// decoding is set when the input is decoded from the --from-encoding and
+	// encoding when the output is encoded in the --to-encoding
+	decoding      bool
+	encoding      bool
+	decodeOptions transcode.Options
+	encodeOptions transcode.Options
 
 	// Debug options
 	verbosity int
 	version   bool
 
+	// Set when any of the operands failed
+	exitStatus int
+
 	rootCmd = &cobra.Command{
-		Use:   "cat",
+		Use:   ProgramName,
 		Short: "Concatenate FILE(s) to standard output.",
 		Long: `Concatenate FILE(s) to standard output.
 
 		With no FILE, or when FILE is -, read standard input.`,
 		Version: VersionNumber,
-		PreRun: func(cmd *cobra.Command, args []string) {
+		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if numberNonBlank {
 				number = false
 			}
+			if showAll {
+				showNonPrinting, showEnds, showTabs = true, true, true
+			}
+			if showEndsAndNonPrinting {
+				showNonPrinting, showEnds = true, true
+			}
+			if showTabsAndNonPrinting {
+				showNonPrinting, showTabs = true, true
+			}
+			if checkUnicode {
+				showUnicode = true
+			}
+
+			if hexWidth < 1 || hexGroup < 0 {
+				return fmt.Errorf("invalid hex layout %d bytes in groups of %d", hexWidth, hexGroup)
+			}
+
+			if err := parseEncodings(cmd); err != nil {
+				return err
+			}
+
+			if err := parseRecordSeparator(cmd); err != nil {
+				return err
+			}
+			if followFile && reverseLines {
+				return fmt.Errorf("the --follow can not be used with --reverse")
+			}
+			if followFile && decompress {
+				// The compressed stream does not grow line by line like a log
+				return fmt.Errorf("the --follow can not be used with --decompress")
+			}
+
+			var err error
+			if eol, err = format.ParseEOL(eolName); err != nil {
+				return err
+			}
+			if highlighting, err = useHighlight(highlightMode); err != nil {
+				return err
+			}
+			if lineRanges, err = rangeutil.ParsePositionList(lines); err != nil {
+				return fmt.Errorf("invalid line list %q: %w", lines, err)
+			}
+			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			// Run the command
-			process(args)
+			// Run the command, the errors are already reported per operand
+			err := process(args)
+			switch {
+			case errors.Is(err, interruptedError):
+				exitStatus = interruptedStatus
+			case err != nil:
+				exitStatus = 1
+			}
 		},
 	}
 )
@@ -56,83 +155,492 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&showEnds, "show-ends", "E", false, "display $ at end of each line")
 	rootCmd.PersistentFlags().BoolVarP(&showTabs, "show-tabs", "T", false, "display TAB characters as ^I")
 	rootCmd.PersistentFlags().BoolVarP(&showNonPrinting, "show-nonprinting", "v", false, "use ^ and M- notation, except for LFD and TAB")
-	rootCmd.PersistentFlags().BoolVarP(&showEndsAndNonPrinting, "show-ends-and-nonprinting", "e", false, "display $, TAB, and ^C")
-	rootCmd.PersistentFlags().BoolVarP(&showTabsAndNonPrinting, "show-tabs-and-nonprinting", "t", false, "display TAB characters as ^I and use ^ and M- notation, except for LFD and TAB")
+	rootCmd.PersistentFlags().BoolVarP(&showEndsAndNonPrinting, "show-ends-and-nonprinting", "e", false, "equivalent to -vE")
+	rootCmd.PersistentFlags().BoolVarP(&showTabsAndNonPrinting, "show-tabs-and-nonprinting", "t", false, "equivalent to -vT")
 	rootCmd.PersistentFlags().BoolVarP(&showAll, "show-all", "A", false, "equivalent to -vET")
+	rootCmd.PersistentFlags().BoolVar(&decompress, "decompress", false, "decompress the gzip, bzip2 and zlib input, other input is copied as is")
+	rootCmd.PersistentFlags().StringVar(&lines, "lines", "", "print only these lines, e.g. 1-10,50,100-, numbered as in the input")
+	rootCmd.PersistentFlags().BoolVar(&headers, "headers", false, "print a header \"==> NAME <==\" ahead of each file, like head -v")
+	rootCmd.PersistentFlags().StringVar(&separator, "separator", "", "print STRING between the files")
+	rootCmd.PersistentFlags().BoolVar(&ensureNewline, "ensure-newline", false, "end each file with a newline, the missing one is added")
+	rootCmd.PersistentFlags().BoolVar(&showUnicode, "show-unicode", false, "display the invisible and misleading Unicode characters as <U+XXXX>")
+	rootCmd.PersistentFlags().BoolVar(&checkUnicode, "check", false, "like --show-unicode, exit with an error if bidirectional controls are found")
+	rootCmd.PersistentFlags().StringVar(&highlightMode, "highlight", "never", "colour the Go, Python, shell, JSON and YAML source when `WHEN` is always, or auto for a terminal")
+	rootCmd.PersistentFlags().Lookup("highlight").NoOptDefVal = "auto"
+	rootCmd.PersistentFlags().BoolVar(&hex, "hex", false, "write a hex dump of the output, with the offset, hex and ASCII columns of xxd")
+	rootCmd.PersistentFlags().IntVar(&hexWidth, "hex-width", 16, "the bytes per line of --hex")
+	rootCmd.PersistentFlags().IntVar(&hexGroup, "hex-group", 2, "the bytes per group of --hex, 0 for no grouping")
+	rootCmd.PersistentFlags().BoolVar(&unhex, "unhex", false, "read the input as a hex dump and write its bytes, like xxd -r")
+	rootCmd.PersistentFlags().StringVar(&fromEncoding, "from-encoding", "auto", "the encoding of the input, utf-8, utf-16le, utf-16be, latin-1 or auto to detect the BOM")
+	rootCmd.PersistentFlags().StringVar(&toEncoding, "to-encoding", "utf-8", "the encoding of the output, utf-8, utf-16le, utf-16be or latin-1")
+	rootCmd.PersistentFlags().BoolVar(&stripBOM, "strip-bom", false, "drop the byte order mark at the start of the input")
+	rootCmd.PersistentFlags().StringVar(&onInvalid, "on-invalid", "replace", "the invalid input and the characters not in the output encoding are replaced, fail or passthrough as is")
+	rootCmd.PersistentFlags().BoolVar(&stripANSI, "strip-ansi", false, "remove the terminal escape sequences, e.g. the colours, -v shows them")
+	rootCmd.PersistentFlags().BoolVar(&reverseLines, "reverse", false, "print the lines of each file from the last to the first, like tac")
+	rootCmd.PersistentFlags().StringVar(&reverseSeparator, "reverse-separator", "", "the separator ending the records reversed by --reverse, the record separator by default")
+	rootCmd.PersistentFlags().BoolVarP(&followFile, "follow", "f", false, "keep printing the data appended to the last file like tail -F, through its rotation and truncation")
+	rootCmd.PersistentFlags().StringArrayVar(&teeFiles, "tee", nil, "also write the output to `FILE`, can be given more than once")
+	rootCmd.PersistentFlags().BoolVar(&appendTee, "append", false, "append to the --tee files instead of overwriting them")
+	rootCmd.PersistentFlags().BoolVarP(&zeroTerminated, "zero-terminated", "z", false, "line delimiter is NUL, not newline")
+	rootCmd.PersistentFlags().StringVar(&recordSeparator, "record-separator", "\n", "the separator ending the lines numbered, squeezed and shown by the formatting")
+	rootCmd.PersistentFlags().StringVar(&eolName, "eol", "keep", "write the line ends as lf, crlf or keep them")
 	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "V", "verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&version, "version", "", false, "output version information and exit")
 
 }
 
-func process(args []string) {
+func process(args []string) error {
 	if len(args) == 0 {
 		args = []string{"-"}
 	}
 
-	linenumber := 0
-	blanks := 0
-	for _, file := range args {
-		if file == "-" {
-			file = os.Stdin.Name()
+	out := newOutput(os.Stdout, formatOptions())
+	failed := false
+	var writeErr writeError
+	for i, file := range args {
+		err := out.cat(file, followFile && i == len(args)-1)
+		// The destinations are reported as they fail
+		if errors.Is(err, outputsFailedError) {
+			failed = true
+			break
+		}
+		// Like GNU cat the output failing stops cat, it is reported once below
+		if errors.As(err, &writeErr) {
+			break
 		}
-		f, err := os.Open(file)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			continue
+			reportError(os.Stderr, file, err)
+			failed = true
+		}
+		// Past the selected lines the rest of the operands are not read
+		if out.linesDone {
+			break
 		}
+	}
 
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			linenumber++
-			line := scanner.Text()
-
-			if line == "" {
-				blanks++
-				if squeezeBlank && blanks > 1 {
-					continue
-				}
-			} else {
-				blanks = 0
-			}
+	err := out.flush()
+	if writeErr.err != nil {
+		err = writeErr
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, outputsFailedError):
+		case errors.As(err, new(writeError)):
+			reportError(os.Stderr, "write error", err)
+		default:
+			// The end of the last file held back fails to encode
+			reportError(os.Stderr, args[len(args)-1], err)
+		}
+		failed = true
+	}
+	if out.tee != nil {
+		out.tee.Close()
+		failed = failed || out.tee.failed
+	}
+	if out.interrupted {
+		return interruptedError
+	}
+	if failed {
+		return operandFailedError
+	}
+	return nil
+}
 
-			// TODO: The linewidth behaviours is different from cat for linnumbers
-			// that are greater than 6 digits. This is could be done like cat, but
-			// the behaviour is not documented
+// output writes the operands to the standard output, the formatting state is
+// carried from one operand to the next
+type output struct {
+	file *os.File
+	w    *bufio.Writer
+	// infos are the output files the input can not be
+	infos     []os.FileInfo
+	opts      format.Options
+	formatter *format.Formatter
+	formatted *format.Writer
+	// closers write what is held back by the writers between w and file, in
+	// the order of the writing
+	closers []io.Closer
+	// tee also writes the output to the --tee files
+	tee *teeWriter
+	// encoder writes the output in the --to-encoding
+	encoder *transcode.Encoder
+	// direct is set when the input is copied to the file as is
+	direct bool
+	// files is the number of operands written so far
+	files int
+	// done stops following the last file, an interrupt does when it is nil
+	done <-chan struct{}
+	// interrupted is set once the following is stopped
+	interrupted bool
+	// linesDone is set once the output is past the last of the --lines
+	linesDone bool
+}
 
-			linewidth := len(fmt.Sprintf("%d", linenumber))
-			if linewidth < 6 {
-				linewidth = 6
-			}
+func newOutput(file *os.File, opts format.Options) *output {
+	o := &output{file: file, opts: opts, formatter: format.New(opts)}
+	var w io.Writer = outputFile{file}
+	outputs := []*os.File{file}
+	if len(teeFiles) > 0 {
+		o.tee = newTee(file, teeFiles, appendTee, os.Stderr)
+		outputs = append(outputs, o.tee.files()...)
+		w = o.tee
+	}
+	// The input is compared with the regular output files only
+	for _, f := range outputs {
+		if info, err := f.Stat(); err == nil {
+			o.infos = append(o.infos, info)
+		}
+	}
 
-			if number {
-				fmt.Printf("%*d\t", linewidth, linenumber)
-			}
+	// The text is encoded ahead of the hex dump, which is of the bytes written
+	if hex {
+		dumper := hexdump.NewDumper(w, hexOptions())
+		o.closers = append(o.closers, dumper)
+		w = dumper
+	}
+	if encoding {
+		o.encoder = transcode.NewEncoder(w, encodeOptions)
+		o.closers = append([]io.Closer{o.encoder}, o.closers...)
+		w = o.encoder
+	}
+	o.w = bufio.NewWriter(w)
+	o.formatted = o.formatter.Writer(o.w)
+	o.direct = !opts.Transforms() && !headers && !ensureNewline && len(o.closers) == 0 && o.tee == nil
+	return o
+}
 
-			if numberNonBlank {
-				if line != "" {
-					fmt.Printf("%*d\t", linewidth, linenumber)
-				} else {
-					continue
-				}
-			}
+// cat writes a single operand to the output, the file is always closed. The
+// "-" reads the standard input from where it is, it is neither reopened nor
+// closed so it can be given more than once. With follow the data appended to
+// a regular file is written until done.
+func (o *output) cat(file string, follow bool) error {
+	f := os.Stdin
+	if file != "-" {
+		var err error
+		if f, err = os.Open(file); err != nil {
+			return err
+		}
+		defer f.Close()
+	}
 
-			if showEnds {
-				line = line + "$"
-			}
+	for _, info := range o.infos {
+		if sameFile(f, info) {
+			return inputIsOutputError
+		}
+	}
 
-			if showTabs {
-				line = strings.ReplaceAll(line, "\t", "^I")
-			}
+	if err := o.startFile(file); err != nil {
+		return o.linesDoneOr(err)
+	}
 
-			if showNonPrinting || showEndsAndNonPrinting || showTabsAndNonPrinting {
-				fmt.Fprintf(os.Stderr, "show-nonprinting not implemented")
-			}
+	var input io.Reader = f
+	if follow && following(f) {
+		done := o.done
+		if done == nil {
+			// The interrupted following still writes the output held back, until
+			// then the signals are left to their default handling
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			done = ctx.Done()
+		}
+		fl := newFollower(file, f, o.w.Flush, done)
+		defer func() {
+			fl.Close()
+			o.interrupted = fl.stopped
+		}()
+		input = fl
+	}
+	if decompress {
+		var err error
+		if input, err = decompressed(f); err != nil {
+			return err
+		}
+	}
+
+	if unhex {
+		input = hexdump.NewReader(input)
+	}
+	if decoding {
+		input = transcode.NewDecoder(input, decodeOptions)
+	}
+
+	var lang *highlight.Language
+	if highlighting {
+		lang, input = detectLanguage(file, input)
+	}
+
+	// The line ends are only known when the input passes the formatter
+	if o.direct && lang == nil && !decoding && !reverseLines {
+		// Anything formatted before is written ahead of the copy
+		if err := o.formatted.Flush(); err != nil {
+			return err
+		}
+		if err := o.w.Flush(); err != nil {
+			return err
+		}
+		return copyFile(o.file, input)
+	}
+	bidiControls := o.formatter.BidiControls()
+	// The lines printed before the end of the --lines are checked too
+	err := o.linesDoneOr(o.format(input, lang))
+	if encodeErr := o.flushEncoded(); err == nil {
+		err = encodeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n := o.formatter.BidiControls() - bidiControls; checkUnicode && n > 0 {
+		return fmt.Errorf("%w: %d found", bidiControlsFoundError, n)
+	}
+	return nil
+}
 
-			fmt.Printf("%s\n", line)
+// linesDoneOr returns err unless it is the end of the --lines, which is set
+// on the output instead
+func (o *output) linesDoneOr(err error) error {
+	if errors.Is(err, format.ErrLinesDone) {
+		o.linesDone = true
+		return nil
+	}
+	return err
+}
+
+// flushEncoded writes the text of the file to the encoder, so that the
+// characters the --to-encoding fails on are reported with the file. The rest
+// of the file is then dropped and the output goes on with the next one.
+func (o *output) flushEncoded() error {
+	if o.encoder == nil {
+		return nil
+	}
+	err := o.w.Flush()
+	if err != nil && !errors.As(err, new(writeError)) {
+		o.w.Reset(o.encoder)
+	}
+	return err
+}
+
+// startFile writes what comes between the files ahead of the file, the missing
+// newline of the previous file, the separator and the header like head -v
+func (o *output) startFile(file string) error {
+	o.files++
+	if o.files == 1 {
+		return o.writeHeader(file, "")
+	}
+
+	if err := o.endFile(); err != nil {
+		return err
+	}
+	if _, err := o.w.WriteString(separator); err != nil {
+		return err
+	}
+	return o.writeHeader(file, "\n")
+}
+
+// endFile adds the newline missing at the end of the last file if asked for.
+// The text held back at the end of the file is continued by the next, e.g. a
+// CR followed by LF is a CRLF, unless something is written between the files.
+func (o *output) endFile() error {
+	if !ensureNewline && separator == "" && !headers {
+		return nil
+	}
+	if err := o.formatted.Flush(); err != nil {
+		return err
+	}
+	if !ensureNewline || o.files == 0 || o.formatter.AtLineStart() {
+		return nil
+	}
+	_, err := o.formatted.Write([]byte(recordSeparator))
+	return err
+}
+
+func (o *output) writeHeader(file string, prefix string) error {
+	if !headers {
+		return nil
+	}
+	if file == "-" {
+		file = "standard input"
+	}
+	if _, err := fmt.Fprintf(o.w, "%s==> %s <==\n", prefix, file); err != nil {
+		return err
+	}
+	// The header ends any line left open by the previous file
+	o.formatter.EndLine()
+	return nil
+}
+
+func (o *output) flush() error {
+	if err := o.endFile(); err != nil {
+		return err
+	}
+	if err := o.formatted.Close(); err != nil {
+		return err
+	}
+	if err := o.w.Flush(); err != nil {
+		return err
+	}
+	for _, c := range o.closers {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatOptions() format.Options {
+	return format.Options{
+		Number:          number,
+		NumberNonBlank:  numberNonBlank,
+		SqueezeBlank:    squeezeBlank,
+		ShowEnds:        showEnds,
+		ShowTabs:        showTabs,
+		ShowNonPrinting: showNonPrinting,
+		ShowUnicode:     showUnicode,
+		StripANSI:       stripANSI,
+		EOL:             eol,
+		Terminator:      []byte(recordSeparator),
+		Lines:           lineRanges,
+	}
+}
+
+// hexOptions returns the layout of --hex, the group 0 is the whole line like
+// the -g 0 of xxd
+func hexOptions() hexdump.Options {
+	if hexGroup == 0 {
+		return hexdump.Options{Width: hexWidth, Group: -1}
+	}
+	return hexdump.Options{Width: hexWidth, Group: hexGroup}
+}
+
+// parseEncodings sets up the decoding and encoding, the input is decoded
+// with any of the flags so that the text is UTF-8 for the formatting and the
+// encoding of the output
+func parseEncodings(cmd *cobra.Command) error {
+	from, err := charset.Parse(fromEncoding)
+	if err != nil {
+		return err
+	}
+	to, err := charset.Parse(toEncoding)
+	if err != nil {
+		return err
+	}
+	policy, err := transcode.ParseInvalid(onInvalid)
+	if err != nil {
+		return err
+	}
+
+	decoding, encoding = false, false
+	for _, name := range []string{"from-encoding", "to-encoding", "strip-bom", "on-invalid"} {
+		decoding = decoding || cmd.Flags().Changed(name)
+	}
+	encoding = to != charset.Auto && to != charset.UTF8
+	decodeOptions = transcode.Options{Encoding: from, StripBOM: stripBOM, OnInvalid: policy}
+	encodeOptions = transcode.Options{Encoding: to, OnInvalid: policy}
+	return nil
+}
 
+// parseRecordSeparator sets the separator of the lines, -z is a NUL separator,
+// and the one reversed by --reverse, which is the same unless given
+func parseRecordSeparator(cmd *cobra.Command) error {
+	if zeroTerminated {
+		if cmd.Flags().Changed("record-separator") && recordSeparator != "\x00" {
+			return fmt.Errorf("the --zero-terminated can not be used with --record-separator")
 		}
+		recordSeparator = "\x00"
+	}
+	if recordSeparator == "" {
+		return fmt.Errorf("the --record-separator can not be empty")
+	}
+	if recordSeparator != "\n" && cmd.Flags().Changed("eol") {
+		return fmt.Errorf("the --eol converts the newline separators only")
+	}
+
+	if cmd.Flags().Changed("reverse-separator") {
+		if reverseSeparator == "" {
+			return fmt.Errorf("the --reverse-separator can not be empty")
+		}
+		return nil
+	}
+	reverseSeparator = recordSeparator
+	return nil
+}
+
+// detectLanguage returns the language of the file and the input to read in
+// its place. The #! line is only looked for without a known extension, in
+// what the first read brings, so that a slow pipe or a followed file is not
+// held back until more of it comes.
+func detectLanguage(file string, input io.Reader) (*highlight.Language, io.Reader) {
+	if lang := highlight.Detect(file, nil); lang != nil {
+		return lang, input
+	}
+	br := bufio.NewReader(input)
+	_, _ = br.Peek(1)
+	head, _ := br.Peek(br.Buffered())
+	return highlight.Detect(file, head), br
+}
+
+// useHighlight reports whether the source is coloured for the --highlight
+// WHEN, the colours are left out with -v which would show the escapes
+func useHighlight(when string) (bool, error) {
+	switch when {
+	case "always":
+		return !showNonPrinting, nil
+	case "auto":
+		return !showNonPrinting && isatty.IsTerminal(os.Stdout.Fd()), nil
+	case "never":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid argument %q for --highlight, valid arguments are always, auto and never", when)
+}
+
+// format writes the input formatted, reversed with --reverse and with the
+// source of lang coloured. The colours come ahead of the formatting so that -n
+// numbers the coloured lines.
+func (o *output) format(input io.Reader, lang *highlight.Language) error {
+	var w io.Writer = o.formatted
+	var hw *highlight.Writer
+	if lang != nil {
+		hw = highlight.NewWriter(w, lang)
+		w = hw
+	}
+
+	var err error
+	if reverseLines {
+		err = reverse(w, input, []byte(reverseSeparator))
+	} else {
+		_, err = io.Copy(w, input)
+	}
+	if err == nil && hw != nil {
+		err = hw.Close()
+	}
+	return err
+}
+
+// copyFile copies the input unchanged, io.Copy between files lets Go use the
+// copy_file_range, sendfile or splice system calls on Linux so the data does
+// not pass through user space
+func copyFile(dst *os.File, src io.Reader) error {
+	_, err := io.Copy(dst, src)
+	var pathErr *os.PathError
+	var syscallErr *os.SyscallError
+	// The system calls copying the data between the files fail on the output,
+	// the input file is only opened for reading
+	if errors.As(err, &pathErr) && pathErr.Op == "write" || errors.As(err, &syscallErr) {
+		return writeError{err}
+	}
+	return err
+}
+
+// outputFile returns the errors of writing to the standard output as write
+// errors
+type outputFile struct {
+	f *os.File
+}
 
+func (o outputFile) Write(p []byte) (int, error) {
+	n, err := o.f.Write(p)
+	if err != nil {
+		return n, writeError{err}
 	}
+	return n, nil
 }
 
 func Execute() {
@@ -141,4 +649,5 @@ func Execute() {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	os.Exit(exitStatus)
 }
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ennc0d3/coding-challenges/cat/format"
+)
+
+// benchmarkFile creates a file of about 32 MiB from the test text
+func benchmarkFile(b *testing.B) string {
+	b.Helper()
+	text, err := os.ReadFile("../tests/testdata/test.txt")
+	if err != nil {
+		b.Fatalf("Unable to read test text, err: %s", err)
+	}
+	fileName := filepath.Join(b.TempDir(), "bench.txt")
+	data := bytes.Repeat(text, 32*1024*1024/len(text))
+	if err := os.WriteFile(fileName, data, 0o600); err != nil {
+		b.Fatalf("Unable to write %s, err: %s", fileName, err)
+	}
+	return fileName
+}
+
+// benchmarkCat runs cat on the benchmark file with the output to a file, the
+// output file is truncated for each run
+func benchmarkCat(b *testing.B, cat func(dst, src *os.File) error) {
+	fileName := benchmarkFile(b)
+	info, err := os.Stat(fileName)
+	if err != nil {
+		b.Fatalf("Unable to stat %s, err: %s", fileName, err)
+	}
+	dst, err := os.Create(filepath.Join(b.TempDir(), "out.txt"))
+	if err != nil {
+		b.Fatalf("Unable to create output, err: %s", err)
+	}
+	defer dst.Close()
+
+	b.SetBytes(info.Size())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src, err := os.Open(fileName)
+		if err != nil {
+			b.Fatalf("Unable to open %s, err: %s", fileName, err)
+		}
+		if err := dst.Truncate(0); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := dst.Seek(0, io.SeekStart); err != nil {
+			b.Fatal(err)
+		}
+		if err := cat(dst, src); err != nil {
+			b.Fatal(err)
+		}
+		src.Close()
+	}
+}
+
+func BenchmarkCopyFile(b *testing.B) {
+	benchmarkCat(b, func(dst, src *os.File) error {
+		return copyFile(dst, src)
+	})
+}
+
+func BenchmarkCatFile(b *testing.B) {
+	benchmarkCat(b, func(dst, src *os.File) error {
+		out := bufio.NewWriter(dst)
+		if _, err := format.New(format.Options{}).Format(out, src); err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// BenchmarkScanLines is the line by line loop with bufio.Scanner that cat
+// used before, kept as the reference for the other benchmarks
+func BenchmarkScanLines(b *testing.B) {
+	benchmarkCat(b, func(dst, src *os.File) error {
+		out := bufio.NewWriter(dst)
+		scanner := bufio.NewScanner(src)
+		for scanner.Scan() {
+			fmt.Fprintf(out, "%s\n", scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/ennc0d3/coding-challenges/cat/highlight"
+	"github.com/spf13/pflag"
 )
 
 func TestProcess(t *testing.T) {
@@ -65,3 +70,749 @@ func TestProcess(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	existing := filepath.Join(tmpDir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("line1\n"), 0o600); err != nil {
+		t.Fatalf("Unable to write %s, err: %s", existing, err)
+	}
+	empty := filepath.Join(tmpDir, "empty.txt")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatalf("Unable to write %s, err: %s", empty, err)
+	}
+	missing := filepath.Join(tmpDir, "missing.txt")
+
+	tests := []struct {
+		name    string
+		args    []string
+		output  string
+		stdout  string
+		stderr  string
+		wantErr bool
+	}{
+		{
+			name:   "existing file",
+			args:   []string{existing},
+			stdout: "line1\n",
+		},
+		{
+			name:    "missing file continues with the next",
+			args:    []string{missing, existing},
+			stdout:  "line1\n",
+			stderr:  "cat: " + missing + ": No such file or directory\n",
+			wantErr: true,
+		},
+		{
+			name:    "directory",
+			args:    []string{tmpDir},
+			stderr:  "cat: " + tmpDir + ": Is a directory\n",
+			wantErr: true,
+		},
+		{
+			name:    "input file is output file",
+			args:    []string{existing},
+			output:  existing,
+			stderr:  "cat: " + existing + ": input file is output file\n",
+			wantErr: true,
+		},
+		{
+			name:   "input file read to its end is output file",
+			args:   []string{empty},
+			output: empty,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldStdout, oldStderr := os.Stdout, os.Stderr
+			defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+
+			outName := filepath.Join(t.TempDir(), "out.txt")
+			if tt.output != "" {
+				outName = tt.output
+			}
+			out, err := os.OpenFile(outName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+			if err != nil {
+				t.Fatalf("Unable to open %s, err: %s", outName, err)
+			}
+			errOut, err := os.Create(filepath.Join(t.TempDir(), "err.txt"))
+			if err != nil {
+				t.Fatalf("Unable to create stderr file, err: %s", err)
+			}
+			os.Stdout, os.Stderr = out, errOut
+
+			err = process(tt.args)
+			out.Close()
+			errOut.Close()
+			os.Stdout, os.Stderr = oldStdout, oldStderr
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.output == "" {
+				if stdout, _ := os.ReadFile(outName); string(stdout) != tt.stdout {
+					t.Errorf("process() stdout = %q, want %q", stdout, tt.stdout)
+				}
+			}
+			if stderr, _ := os.ReadFile(errOut.Name()); string(stderr) != tt.stderr {
+				t.Errorf("process() stderr = %q, want %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+// TestWriteError checks that the output failing is reported once as a write
+// error and stops cat, the copy and the formatting alike
+func TestWriteError(t *testing.T) {
+	full, err := os.OpenFile("/dev/full", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no /dev/full to fail the writing")
+	}
+	defer full.Close()
+	large := filepath.Join(t.TempDir(), "large.txt")
+	if err := os.WriteFile(large, bytes.Repeat([]byte("line\n"), 20000), 0o600); err != nil {
+		t.Fatalf("Unable to write %s, err: %s", large, err)
+	}
+
+	for _, args := range [][]string{
+		{"testdata/noeol.txt", "testdata/noeol.txt"},
+		{"-n", "testdata/noeol.txt", "testdata/noeol.txt"},
+		{"-n", large, "testdata/noeol.txt"},
+		{large, "missing.txt"},
+	} {
+		parseArgs(t, args...)
+		oldStdout, oldStderr := os.Stdout, os.Stderr
+		errOut, err := os.Create(filepath.Join(t.TempDir(), "err.txt"))
+		if err != nil {
+			t.Fatalf("Unable to create stderr file, err: %s", err)
+		}
+		os.Stdout, os.Stderr = full, errOut
+		err = process(rootCmd.Flags().Args())
+		os.Stdout, os.Stderr = oldStdout, oldStderr
+		errOut.Close()
+
+		if err == nil {
+			t.Errorf("cat %v to /dev/full should fail", args)
+		}
+		const expected = "cat: write error: No space left on device\n"
+		if stderr, _ := os.ReadFile(errOut.Name()); string(stderr) != expected {
+			t.Errorf("cat %v stderr = %q, want %q", args, stderr, expected)
+		}
+	}
+}
+
+// runCat runs cat with the command line args and returns its output, the flags
+// are reset to their defaults first
+func runCat(t *testing.T, args ...string) string {
+	t.Helper()
+	stdout, stderr, err := runCatErr(t, args...)
+	if err != nil {
+		t.Errorf("cat %v failed: %v, stderr: %q", args, err, stderr)
+	}
+	return stdout
+}
+
+// runCatErr runs cat like runCat and also returns the standard error and the
+// error of process
+func runCatErr(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	parseArgs(t, args...)
+
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+	out, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatalf("Unable to create output, err: %s", err)
+	}
+	defer out.Close()
+	errOut, err := os.Create(filepath.Join(t.TempDir(), "err.txt"))
+	if err != nil {
+		t.Fatalf("Unable to create stderr file, err: %s", err)
+	}
+	defer errOut.Close()
+	os.Stdout, os.Stderr = out, errOut
+
+	err = process(rootCmd.Flags().Args())
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+
+	stdout, readErr := os.ReadFile(out.Name())
+	if readErr != nil {
+		t.Fatalf("Unable to read output, err: %s", readErr)
+	}
+	stderr, readErr := os.ReadFile(errOut.Name())
+	if readErr != nil {
+		t.Fatalf("Unable to read stderr, err: %s", readErr)
+	}
+	return string(stdout), string(stderr), err
+}
+
+// parseArgs sets the flags from args alone, the ones of the previous runs are
+// reset to their defaults
+func parseArgs(t *testing.T, args ...string) {
+	t.Helper()
+	rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		// Set appends to the values of a repeated flag
+		if values, ok := f.Value.(pflag.SliceValue); ok {
+			_ = values.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+	if err := rootCmd.ParseFlags(args); err != nil {
+		t.Fatalf("Unable to parse %v, err: %s", args, err)
+	}
+	if err := rootCmd.PreRunE(rootCmd, rootCmd.Flags().Args()); err != nil {
+		t.Fatalf("Invalid args %v, err: %s", args, err)
+	}
+}
+
+// TestGolden compares the output with the one of GNU cat for the same args,
+// saved in testdata/NAME.golden
+func TestGolden(t *testing.T) {
+	const data = "../tests/testdata/"
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"number_test", []string{"-n", data + "test.txt"}},
+		{"nonblank_specialchars", []string{"-b", data + "test_specialchars.txt"}},
+		{"squeeze_specialchars", []string{"-s", data + "test_specialchars.txt"}},
+		{"squeeze_number_specialchars", []string{"-sn", data + "test_specialchars.txt"}},
+		{"squeeze_nonblank_specialchars", []string{"-sb", data + "test_specialchars.txt"}},
+		{"squeeze_number_nonblank_specialchars", []string{"-snb", data + "test_specialchars.txt"}},
+		{"squeeze_ends_specialchars", []string{"-sE", data + "test_specialchars.txt"}},
+		{"number_multiple_files", []string{"-n", data + "test.txt", data + "test2.txt"}},
+		{"nonblank_multiple_files", []string{"-b", data + "test.txt", data + "test_specialchars.txt", data + "test2.txt"}},
+		{"squeeze_number_across_files", []string{"-sn", "testdata/blanks.txt", "testdata/blanks.txt"}},
+		{"number_line_across_files", []string{"-n", "testdata/noeol.txt", "testdata/noeol.txt"}},
+		{"nonblank_line_across_files", []string{"-b", "testdata/noeol.txt", "testdata/blanks.txt", "testdata/noeol.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := os.ReadFile(filepath.Join("testdata", tt.name+".golden"))
+			if err != nil {
+				t.Fatalf("Unable to read golden file, err: %s", err)
+			}
+			if actual := runCat(t, tt.args...); actual != string(expected) {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, expected)
+			}
+		})
+	}
+}
+
+func TestProcessStdin(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "file.txt")
+	if err := os.WriteFile(file, []byte("file\n"), 0o600); err != nil {
+		t.Fatalf("Unable to write %s, err: %s", file, err)
+	}
+
+	pipeStdin := func(t *testing.T) *os.File {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("Unable to create pipe, err: %s", err)
+		}
+		go func() {
+			_, _ = w.Write([]byte("stdin\n"))
+			w.Close()
+		}()
+		return r
+	}
+
+	// A regular file already partially read by the parent, e.g. (read x; cat -) < file
+	fileStdin := func(t *testing.T) *os.File {
+		stdinFile := filepath.Join(tmpDir, "stdin.txt")
+		if err := os.WriteFile(stdinFile, []byte("skip\nstdin\n"), 0o600); err != nil {
+			t.Fatalf("Unable to write %s, err: %s", stdinFile, err)
+		}
+		f, err := os.Open(stdinFile)
+		if err != nil {
+			t.Fatalf("Unable to open %s, err: %s", stdinFile, err)
+		}
+		if _, err := f.Seek(int64(len("skip\n")), io.SeekStart); err != nil {
+			t.Fatalf("Unable to seek %s, err: %s", stdinFile, err)
+		}
+		return f
+	}
+
+	tests := []struct {
+		name     string
+		stdin    func(t *testing.T) *os.File
+		args     []string
+		expected string
+	}{
+		{name: "pipe", stdin: pipeStdin, args: []string{"-"}, expected: "stdin\n"},
+		{name: "pipe without args", stdin: pipeStdin, args: []string{}, expected: "stdin\n"},
+		{name: "pipe interleaved", stdin: pipeStdin, args: []string{file, "-", file, "-"}, expected: "file\nstdin\nfile\n"},
+		{name: "pipe numbered", stdin: pipeStdin, args: []string{"-n", "-", file, "-"}, expected: "     1\tstdin\n     2\tfile\n"},
+		{name: "partially read file", stdin: fileStdin, args: []string{"-", file}, expected: "stdin\nfile\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := os.Stdin
+			defer func() { os.Stdin = old }()
+			os.Stdin = tt.stdin(t)
+			defer os.Stdin.Close()
+
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "numbered as in the input",
+			args:     []string{"-n", "--lines", "2,4-", "testdata/blanks.txt"},
+			expected: "     2\t\n     4\tmiddle\n     5\t\n     6\t\n",
+		},
+		{
+			name:     "across files and stops after the last",
+			args:     []string{"--lines", "1-2", "testdata/noeol.txt", "testdata/noeol.txt", "missing.txt"},
+			expected: "first\nsecondfirst\n",
+		},
+		{
+			name:     "squeezed within the selection",
+			args:     []string{"-s", "--lines", "1-3", "testdata/blanks.txt"},
+			expected: "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHeadersAndSeparators(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "headers like head -v",
+			args:     []string{"--headers", "testdata/noeol.txt", "testdata/noeol.txt"},
+			expected: "==> testdata/noeol.txt <==\nfirst\nsecond\n==> testdata/noeol.txt <==\nfirst\nsecond",
+		},
+		{
+			name:     "headers numbered",
+			args:     []string{"--headers", "-n", "testdata/noeol.txt", "testdata/noeol.txt"},
+			expected: "==> testdata/noeol.txt <==\n     1\tfirst\n     2\tsecond\n==> testdata/noeol.txt <==\n     3\tfirst\n     4\tsecond",
+		},
+		{
+			name:     "separator only between the files",
+			args:     []string{"--separator", "--\n", "testdata/noeol.txt", "testdata/noeol.txt"},
+			expected: "first\nsecond--\nfirst\nsecond",
+		},
+		{
+			name:     "separator with ensured newline",
+			args:     []string{"--separator", "--\n", "--ensure-newline", "testdata/noeol.txt", "testdata/noeol.txt"},
+			expected: "first\nsecond\n--\nfirst\nsecond\n",
+		},
+		{
+			name:     "ensured newline shows the end",
+			args:     []string{"--ensure-newline", "-E", "testdata/noeol.txt"},
+			expected: "first$\nsecond$\n",
+		},
+		{
+			name:     "ensured newline keeps complete lines",
+			args:     []string{"--ensure-newline", "testdata/blanks.txt", "testdata/blanks.txt"},
+			expected: "\n\n\nmiddle\n\n\n\n\n\nmiddle\n\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShowUnicode(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		failed   bool
+	}{
+		{
+			name:     "zero width joiner",
+			args:     []string{"--show-unicode", "../../cut/tests/testdata/facepalm_zwj.txt"},
+			expected: "🤦🏼<U+200D>♂<U+FE0F>\n",
+		},
+		{
+			name:     "special characters unchanged",
+			args:     []string{"--show-unicode", "--lines", "10", "../tests/testdata/test_specialchars.txt"},
+			expected: "10- With a tab[\t]and ctrl-M[\r]\n",
+		},
+		{
+			name:     "check passes without bidi controls",
+			args:     []string{"--check", "../../cut/tests/testdata/facepalm_zwj.txt"},
+			expected: "🤦🏼<U+200D>♂<U+FE0F>\n",
+		},
+		{
+			name: "check fails on bidi controls",
+			args: []string{"--check", "-n", "testdata/bidi.txt"},
+			expected: "     1\tif access != \"user<U+202E> <U+2066>// admin<U+2069> <U+2066>\" {\n" +
+				"     2\t\tprint(\"p<U+0430>ypal\")\n     3\t}\n",
+			failed: true,
+		},
+		{
+			name:     "check fails on the selected lines",
+			args:     []string{"--check", "--lines", "1", "testdata/bidi.txt", "testdata/bidi.txt"},
+			expected: "if access != \"user<U+202E> <U+2066>// admin<U+2069> <U+2066>\" {\n",
+			failed:   true,
+		},
+		{
+			name:     "check passes past the selected lines",
+			args:     []string{"--check", "--lines", "2-", "testdata/bidi.txt"},
+			expected: "\tprint(\"p<U+0430>ypal\")\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, stderr, err := runCatErr(t, tt.args...)
+			if actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+			if failed := err != nil; failed != tt.failed {
+				t.Errorf("cat %v failed = %v, want %v, stderr: %q", tt.args, failed, tt.failed, stderr)
+			}
+			if tt.failed && stderr != "cat: testdata/bidi.txt: bidirectional control characters: 4 found\n" {
+				t.Errorf("cat %v stderr = %q", tt.args, stderr)
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	const coloured = "\x1b[36m#!/bin/sh\x1b[0m\n\x1b[36m# greet\x1b[0m\n\n\n" +
+		"\x1b[34mfor\x1b[0m name \x1b[34min\x1b[0m \x1b[32m\"$@\"\x1b[0m; \x1b[34mdo\x1b[0m\n" +
+		"\techo \x1b[32m\"hello $name\"\x1b[0m\n\x1b[34mdone\x1b[0m\n"
+	plain, err := os.ReadFile("testdata/greet")
+	if err != nil {
+		t.Fatalf("Unable to read testdata/greet, err: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "shebang",
+			args:     []string{"--highlight=always", "testdata/greet"},
+			expected: coloured,
+		},
+		{
+			name: "numbered and squeezed",
+			args: []string{"--highlight=always", "-sn", "testdata/greet"},
+			expected: "     1\t\x1b[36m#!/bin/sh\x1b[0m\n     2\t\x1b[36m# greet\x1b[0m\n     3\t\n" +
+				"     4\t\x1b[34mfor\x1b[0m name \x1b[34min\x1b[0m \x1b[32m\"$@\"\x1b[0m; \x1b[34mdo\x1b[0m\n" +
+				"     5\t\techo \x1b[32m\"hello $name\"\x1b[0m\n     6\t\x1b[34mdone\x1b[0m\n",
+		},
+		{
+			name:     "auto is off when not a terminal",
+			args:     []string{"--highlight", "testdata/greet"},
+			expected: string(plain),
+		},
+		{
+			name:     "off with nonprinting",
+			args:     []string{"--highlight=always", "-v", "testdata/greet"},
+			expected: string(plain),
+		},
+		{
+			name:     "unknown language",
+			args:     []string{"--highlight=always", "testdata/noeol.txt"},
+			expected: "first\nsecond",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+// firstReadOnly is a slow input with data for a single read, a second one
+// fails the test as it would wait for more
+type firstReadOnly struct {
+	t     *testing.T
+	data  string
+	reads int
+}
+
+func (r *firstReadOnly) Read(p []byte) (int, error) {
+	if r.reads++; r.reads > 1 {
+		r.t.Fatalf("the input was read %d times", r.reads)
+	}
+	return copy(p, r.data), nil
+}
+
+func TestDetectLanguageReads(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		expected *highlight.Language
+		reads    int
+	}{
+		{name: "extension", file: "main.go", data: "pack", expected: highlight.Go, reads: 0},
+		{name: "shebang in the first read", file: "greet", data: "#!/bin/sh\nec", expected: highlight.Shell, reads: 1},
+		{name: "unknown", file: "notes", data: "tex", expected: nil, reads: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &firstReadOnly{t: t, data: tt.data}
+			if lang, _ := detectLanguage(tt.file, r); lang != tt.expected {
+				t.Errorf("detectLanguage(%q) = %v, want %v", tt.file, lang, tt.expected)
+			}
+			if r.reads != tt.reads {
+				t.Errorf("detectLanguage(%q) read %d times, want %d", tt.file, r.reads, tt.reads)
+			}
+		})
+	}
+}
+
+func TestHex(t *testing.T) {
+	const bytesFile = "../tests/testdata/bytes.txt"
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "xxd layout", args: []string{"--hex", bytesFile}, expected: []string{"testdata/bytes_hex.golden"}},
+		{
+			name:     "width and group",
+			args:     []string{"--hex", "--hex-width", "10", "--hex-group", "4", bytesFile},
+			expected: []string{"testdata/bytes_hex_10_4.golden"},
+		},
+		{name: "unhex", args: []string{"--unhex", "testdata/bytes_hex_10_4.golden"}, expected: []string{bytesFile}},
+		{
+			name:     "unhex of several dumps",
+			args:     []string{"--unhex", "testdata/bytes_hex.golden", "testdata/bytes_hex_10_4.golden"},
+			expected: []string{bytesFile, bytesFile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The expected output is the content of the files
+			var expected []byte
+			for _, file := range tt.expected {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatalf("Unable to read %s, err: %s", file, err)
+				}
+				expected = append(expected, data...)
+			}
+			if actual := runCat(t, tt.args...); actual != string(expected) {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, expected)
+			}
+		})
+	}
+}
+
+func TestUnhexInvalidDump(t *testing.T) {
+	actual, stderr, err := runCatErr(t, "--unhex", "testdata/noeol.txt")
+	if actual != "" || err == nil {
+		t.Errorf("cat --unhex testdata/noeol.txt = %q, %v, want an error", actual, err)
+	}
+	if expected := "cat: testdata/noeol.txt: hexdump: invalid dump line 1: no offset\n"; stderr != expected {
+		t.Errorf("cat --unhex testdata/noeol.txt stderr = %q, want %q", stderr, expected)
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		failed   bool
+		stderr   string
+	}{
+		{
+			name:     "utf16 with bom to utf8",
+			args:     []string{"--strip-bom", "testdata/windows_utf16.txt"},
+			expected: "name,city\r\nJosé,Zürich\r\n",
+		},
+		{
+			name:     "decoded ahead of the formatting",
+			args:     []string{"--strip-bom", "-n", "-E", "testdata/windows_utf16.txt"},
+			expected: "     1\tname,city^M$\n     2\tJosé,Zürich^M$\n",
+		},
+		{
+			name:     "latin1 output",
+			args:     []string{"--to-encoding", "latin-1", "testdata/windows_utf16.txt"},
+			expected: "name,city\r\nJos\xe9,Z\xfcrich\r\n",
+		},
+		{
+			name:     "utf16be output",
+			args:     []string{"--from-encoding", "latin1", "--to-encoding", "utf-16be", "-n", "testdata/noeol.txt"},
+			expected: "\x00 \x00 \x00 \x00 \x00 \x001\x00\t\x00f\x00i\x00r\x00s\x00t\x00\n\x00 \x00 \x00 \x00 \x00 \x002\x00\t\x00s\x00e\x00c\x00o\x00n\x00d",
+		},
+		{
+			name:     "forced encoding",
+			args:     []string{"--from-encoding", "utf-16le", "testdata/noeol.txt"},
+			expected: "楦獲ੴ敳潣摮",
+		},
+		{
+			name:     "invalid input fails the file",
+			args:     []string{"--on-invalid", "fail", "testdata/lines.zlib", "testdata/noeol.txt"},
+			expected: "xfirst\nsecond",
+			failed:   true,
+			stderr:   "cat: testdata/lines.zlib: transcode: invalid UTF-8 at byte 1\n",
+		},
+		{
+			name:     "unencodable output fails the file",
+			args:     []string{"--to-encoding", "latin-1", "--on-invalid", "fail", "testdata/crlf.txt", "testdata/euro.txt", "testdata/noeol.txt"},
+			expected: "1\r\nprice: 5 first\nsecond",
+			failed:   true,
+			stderr:   "cat: testdata/euro.txt: transcode: can not encode U+20AC in Latin-1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, stderr, err := runCatErr(t, tt.args...)
+			if actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+			if failed := err != nil; failed != tt.failed {
+				t.Errorf("cat %v failed = %v, want %v, stderr: %q", tt.args, failed, tt.failed, stderr)
+			}
+			if stderr != tt.stderr {
+				t.Errorf("cat %v stderr = %q, want %q", tt.args, stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestEOL(t *testing.T) {
+	const crlf = "testdata/crlf.txt"
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "show ends of crlf", args: []string{"-E", crlf}, expected: "1^M$\n"},
+		{name: "to lf", args: []string{"--eol", "lf", crlf, "testdata/noeol.txt"}, expected: "1\nfirst\nsecond"},
+		{name: "to crlf", args: []string{"--eol=crlf", crlf, "testdata/noeol.txt"}, expected: "1\r\nfirst\r\nsecond"},
+		{name: "mixed to lf shown", args: []string{"--eol=lf", "-E", crlf, "testdata/blanks.txt"}, expected: "1$\n$\n$\n$\nmiddle$\n$\n$\n"},
+		{name: "crlf across files", args: []string{"-E", "testdata/cr.txt", "testdata/blanks.txt"}, expected: "a^M$\n$\n$\nmiddle$\n$\n$\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestANSI(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "stripped",
+			args:     []string{"--strip-ansi", "testdata/ci.log"},
+			expected: "PASS ok\r\n\nlink done\n",
+		},
+		{
+			name: "shown",
+			args: []string{"-v", "testdata/ci.log"},
+			expected: "^[[32mPASS^[[0m ok^M\n^[]0;title^G^[[1;31m^[[0m\n" +
+				"^[]8;;http://x^[\\link^[]8;;^[\\ ^[(Bdone^[7\n",
+		},
+		{
+			name:     "stripped lines squeezed",
+			args:     []string{"--strip-ansi", "-s", "-b", "testdata/ci.log", "testdata/ci.log"},
+			expected: "     1\tPASS ok\r\n\n     2\tlink done\n     3\tPASS ok\r\n\n     4\tlink done\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecordSeparator(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "nul records numbered and squeezed",
+			args:     []string{"-z", "-sn", "testdata/records.txt"},
+			expected: "     1\tfirst\nline\x00     2\t\x00     3\tsecond\x00     4\tlast",
+		},
+		{
+			name:     "nul records shown",
+			args:     []string{"-zA", "testdata/records.txt"},
+			expected: "first\nline$\x00$\x00$\x00second$\x00last",
+		},
+		{
+			name:     "nul records selected",
+			args:     []string{"-z", "--lines", "4-", "testdata/records.txt"},
+			expected: "second\x00last",
+		},
+		{
+			name:     "nul records reversed",
+			args:     []string{"-z", "--reverse", "testdata/records.txt"},
+			expected: "lastsecond\x00\x00\x00first\nline\x00",
+		},
+		{
+			name:     "missing nul added",
+			args:     []string{"-z", "--ensure-newline", "testdata/records.txt", "testdata/noeol.txt"},
+			expected: "first\nline\x00\x00\x00second\x00last\x00first\nsecond\x00",
+		},
+		{
+			name:     "custom separator",
+			args:     []string{"--record-separator", "ec", "-bE", "testdata/noeol.txt"},
+			expected: "     1\tfirst\ns$ec     2\tond",
+		},
+		{
+			name:     "lines reversed by another separator",
+			args:     []string{"-z", "--reverse", "--reverse-separator", "\n", "testdata/records.txt"},
+			expected: "line\x00\x00\x00second\x00lastfirst\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := runCat(t, tt.args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", tt.args, actual, tt.expected)
+			}
+		})
+	}
+}
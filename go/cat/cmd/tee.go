@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"io"
+	"os"
+)
+
+// teeWriter writes to the standard output and the --tee files like tee, a
+// destination failing is reported once and left out while the writing goes on
+// to the others
+type teeWriter struct {
+	dests  []*teeDest
+	errOut io.Writer
+	// failed is set once any destination failed
+	failed bool
+}
+
+type teeDest struct {
+	name string
+	w    io.Writer
+	// file is the --tee file closed by the teeWriter
+	file *os.File
+	err  error
+}
+
+// newTee opens the files, truncated or appended to, a file failing to open is
+// reported and left out
+func newTee(stdout *os.File, files []string, appendTo bool, errOut io.Writer) *teeWriter {
+	t := &teeWriter{errOut: errOut}
+	t.dests = append(t.dests, &teeDest{name: "standard output", w: stdout})
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendTo {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	for _, name := range files {
+		f, err := os.OpenFile(name, flag, 0o666)
+		if err != nil {
+			t.fail(&teeDest{name: name}, err)
+			continue
+		}
+		t.dests = append(t.dests, &teeDest{name: name, w: f, file: f})
+	}
+	return t
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	written := false
+	for _, d := range t.dests {
+		if d.err != nil {
+			continue
+		}
+		if _, err := d.w.Write(p); err != nil {
+			t.fail(d, err)
+			continue
+		}
+		written = true
+	}
+	if !written {
+		return 0, outputsFailedError
+	}
+	return len(p), nil
+}
+
+// Close closes the --tee files, the standard output is left open
+func (t *teeWriter) Close() error {
+	for _, d := range t.dests {
+		if d.file == nil {
+			continue
+		}
+		if err := d.file.Close(); err != nil && d.err == nil {
+			t.fail(d, err)
+		}
+	}
+	return nil
+}
+
+// files returns the --tee files open for writing
+func (t *teeWriter) files() []*os.File {
+	var files []*os.File
+	for _, d := range t.dests {
+		if d.file != nil && d.err == nil {
+			files = append(files, d.file)
+		}
+	}
+	return files
+}
+
+func (t *teeWriter) fail(d *teeDest, err error) {
+	d.err = err
+	t.failed = true
+	reportError(t.errOut, d.name, err)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		existing string
+		expected string
+		file     string
+	}{
+		{
+			name:     "overwritten",
+			args:     []string{"-n", "testdata/noeol.txt"},
+			existing: "old\n",
+			expected: "     1\tfirst\n     2\tsecond",
+			file:     "     1\tfirst\n     2\tsecond",
+		},
+		{
+			name:     "appended",
+			args:     []string{"--append", "testdata/noeol.txt"},
+			existing: "old\n",
+			expected: "first\nsecond",
+			file:     "old\nfirst\nsecond",
+		},
+		{
+			name:     "hex dump",
+			args:     []string{"--hex", "testdata/noeol.txt"},
+			expected: "00000000: 6669 7273 740a 7365 636f 6e64            first.second\n",
+			file:     "00000000: 6669 7273 740a 7365 636f 6e64            first.second\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}
+			args := tt.args
+			for _, file := range files {
+				if tt.existing != "" {
+					if err := os.WriteFile(file, []byte(tt.existing), 0o600); err != nil {
+						t.Fatalf("Unable to write %s, err: %s", file, err)
+					}
+				}
+				args = append([]string{"--tee", file}, args...)
+			}
+
+			if actual := runCat(t, args...); actual != tt.expected {
+				t.Errorf("cat %v = %q, want %q", args, actual, tt.expected)
+			}
+			for _, file := range files {
+				actual, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatalf("Unable to read %s, err: %s", file, err)
+				}
+				if string(actual) != tt.file {
+					t.Errorf("cat %v wrote %q to %s, want %q", args, actual, file, tt.file)
+				}
+			}
+		})
+	}
+}
+
+func TestTeeFailures(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full to fail the writing")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "out.txt")
+	missing := filepath.Join(dir, "missing", "out.txt")
+	args := []string{"--tee", "/dev/full", "--tee", missing, "--tee", file, "testdata/noeol.txt"}
+
+	stdout, stderr, err := runCatErr(t, args...)
+	if err == nil {
+		t.Errorf("cat %v should fail", args)
+	}
+	if expected := "first\nsecond"; stdout != expected {
+		t.Errorf("cat %v = %q, want %q", args, stdout, expected)
+	}
+	expected := "cat: " + missing + ": No such file or directory\n" +
+		"cat: /dev/full: No space left on device\n"
+	if stderr != expected {
+		t.Errorf("cat %v stderr = %q, want %q", args, stderr, expected)
+	}
+	// The other destinations are still written
+	if actual, _ := os.ReadFile(file); string(actual) != "first\nsecond" {
+		t.Errorf("cat %v wrote %q to %s", args, actual, file)
+	}
+}
+
+func TestTeeInputIsOutput(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(file, []byte("data\n"), 0o600); err != nil {
+		t.Fatalf("Unable to write %s, err: %s", file, err)
+	}
+	_, stderr, err := runCatErr(t, "--append", "--tee", file, file)
+	if err == nil {
+		t.Errorf("cat of its --tee file should fail")
+	}
+	if expected := "cat: " + file + ": input file is output file\n"; stderr != expected {
+		t.Errorf("cat stderr = %q, want %q", stderr, expected)
+	}
+}
@@ -0,0 +1,118 @@
+package format
+
+import "bytes"
+
+const esc = 0x1b
+
+// maxHeldSequence is the longest escape sequence held back for the next write
+// when cut short, a longer one is stripped up to the end of the write
+const maxHeldSequence = 4096
+
+// sequenceLength returns the length of the ECMA-48 escape sequence at the start
+// of b, which starts with ESC, and whether it is complete. These are the CSI
+// ESC [ ... with its final byte, the OSC ESC ] and the other control strings
+// ended by BEL or ST, and ESC followed by intermediates and a final byte. A
+// malformed sequence ends before the unexpected byte.
+func sequenceLength(b []byte) (int, bool) {
+	if len(b) < 2 {
+		return len(b), false
+	}
+	switch c := b[1]; {
+	case c == '[':
+		for i := 2; i < len(b); i++ {
+			switch c := b[i]; {
+			case c >= 0x40 && c <= 0x7e:
+				return i + 1, true
+			case c < 0x20 || c > 0x7e:
+				return i, true
+			}
+		}
+	case c == ']' || c == 'P' || c == 'X' || c == '^' || c == '_':
+		for i := 2; i < len(b); i++ {
+			switch {
+			case b[i] == 0x07:
+				return i + 1, true
+			case b[i] == esc && i+1 < len(b) && b[i+1] == '\\':
+				return i + 2, true
+			}
+		}
+	case c >= 0x20 && c <= 0x2f:
+		for i := 2; i < len(b); i++ {
+			switch c := b[i]; {
+			case c >= 0x30 && c <= 0x7e:
+				return i + 1, true
+			case c < 0x20 || c > 0x2f:
+				return i, true
+			}
+		}
+	case c >= 0x30 && c <= 0x7e:
+		return 2, true
+	default:
+		// A lone ESC
+		return 1, true
+	}
+	return len(b), false
+}
+
+// stripANSI appends text without its escape sequences to dst
+func stripANSI(dst []byte, text []byte) []byte {
+	for {
+		i := bytes.IndexByte(text, esc)
+		if i < 0 {
+			return append(dst, text...)
+		}
+		dst = append(dst, text[:i]...)
+		n, _ := sequenceLength(text[i:])
+		text = text[i+n:]
+	}
+}
+
+// partialSequence returns the length of the escape sequence cut short at the
+// end of text
+func partialSequence(text []byte) int {
+	for {
+		i := bytes.IndexByte(text, esc)
+		if i < 0 {
+			return 0
+		}
+		n, complete := sequenceLength(text[i:])
+		if !complete {
+			return len(text) - i
+		}
+		text = text[i+n:]
+	}
+}
+
+// onlySequences reports whether text is made of escape sequences only
+func onlySequences(text []byte) bool {
+	for len(text) > 0 {
+		if text[0] != esc {
+			return false
+		}
+		n, _ := sequenceLength(text)
+		text = text[n:]
+	}
+	return true
+}
+
+// trailingCR returns the length of the end of text that is a CR followed by
+// escape sequences only, which is a CR at the end of the text once stripped
+func trailingCR(text []byte) int {
+	cr := -1
+	for i := 0; i < len(text); {
+		if text[i] == esc {
+			n, _ := sequenceLength(text[i:])
+			i += n
+			continue
+		}
+		cr = -1
+		if text[i] == '\r' {
+			cr = i
+		}
+		i++
+	}
+	if cr < 0 {
+		return 0
+	}
+	return len(text) - cr
+}
@@ -0,0 +1,85 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain", input: "no escapes", expected: "no escapes"},
+		{name: "colours", input: "\x1b[1;31mred\x1b[0m text", expected: "red text"},
+		{name: "cursor movement", input: "a\x1b[2Kb\x1b[10;20Hc\x1b[?25l", expected: "abc"},
+		{name: "osc ended by bel", input: "\x1b]0;window title\x07text", expected: "text"},
+		{name: "osc hyperlink ended by st", input: "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", expected: "link"},
+		{name: "charset and two byte sequences", input: "\x1b(Ba\x1b7b\x1b=", expected: "ab"},
+		{name: "lone esc", input: "a\x1b\x01b", expected: "a\x01b"},
+		{name: "malformed csi", input: "a\x1b[3\x01b", expected: "a\x01b"},
+		{name: "cut short at the end", input: "a\x1b[31", expected: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := stripANSI(nil, []byte(tt.input)); string(actual) != tt.expected {
+				t.Errorf("stripANSI(%q) = %q, want %q", tt.input, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPartialSequence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{input: "text", expected: 0},
+		{input: "a\x1b[0m", expected: 0},
+		{input: "a\x1b", expected: 1},
+		{input: "a\x1b[0m\x1b[31", expected: 4},
+		{input: "\x1b]0;title", expected: 9},
+		{input: "\x1b]0;title\x1b", expected: 10},
+	}
+
+	for _, tt := range tests {
+		if actual := partialSequence([]byte(tt.input)); actual != tt.expected {
+			t.Errorf("partialSequence(%q) = %d, want %d", tt.input, actual, tt.expected)
+		}
+	}
+}
+
+// TestStripANSISplitWrites checks that the sequences split across the writes
+// are stripped as when written at once
+func TestStripANSISplitWrites(t *testing.T) {
+	opts := Options{StripANSI: true, NumberNonBlank: true, SqueezeBlank: true, ShowEnds: true}
+	input := "\x1b[32mok\x1b[0m\r\n\x1b[0m\n\n\x1b]8;;url\x1b\\\x1b[0m\n\x1b]8;;url\x1b\\link\x1b]8;;\x1b\\\nx\r\x1b[0m\nend\x1b[0"
+	expected := "     1\tok^M$\n$\n     2\tlink$\n     3\tx^M$\n     4\tend"
+
+	for size := 1; size < len(input); size++ {
+		var actual bytes.Buffer
+		w := New(opts).Writer(&actual)
+		for i := 0; i < len(input); i += size {
+			if _, err := w.Write([]byte(input[i:min(i+size, len(input))])); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		if actual.String() != expected {
+			t.Errorf("writes of %d bytes = %q, want %q", size, actual.String(), expected)
+		}
+	}
+
+	var actual bytes.Buffer
+	if _, err := New(opts).Format(&actual, strings.NewReader(input)); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	if actual.String() != expected {
+		t.Errorf("Format() = %q, want %q", actual.String(), expected)
+	}
+}
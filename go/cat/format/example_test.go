@@ -0,0 +1,20 @@
+package format_test
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ennc0d3/coding-challenges/cat/format"
+)
+
+// Rendering a stream like cat -A
+func ExampleFormatter_Reader() {
+	f := format.New(format.Options{ShowNonPrinting: true, ShowEnds: true, ShowTabs: true})
+	log := strings.NewReader("level=info\tmsg=\"ok\"\r\n\x1b[31merror\x1b[0m\n")
+
+	_, _ = io.Copy(os.Stdout, f.Reader(log))
+	// Output:
+	// level=info^Imsg="ok"^M$
+	// ^[[31merror^[[0m$
+}
@@ -0,0 +1,471 @@
+// Package format renders text the way GNU cat does with its formatting
+// options, numbering the lines, squeezing the blank lines and showing the
+// line ends, tabs and nonprinting characters. It can also reveal the invisible
+// and misleading Unicode characters as <U+XXXX>.
+//
+// A Formatter keeps the state of the current line, so the same Formatter can
+// be used for several inputs, e.g. files, and the lines continue across them
+// as they do with cat.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/enncod3/coding-challenges/cut/rangeutil"
+)
+
+// ErrLinesDone is returned by the Writer once the input is past the last of
+// the selected Lines, the rest of the input need not be read
+var ErrLinesDone = errors.New("format: past the last selected line")
+
+// Options are the formatting options, the zero value copies the input unchanged
+type Options struct {
+	// Number all the output lines, -n
+	Number bool
+	// NumberNonBlank numbers the nonempty output lines and overrides Number, -b
+	NumberNonBlank bool
+	// SqueezeBlank suppresses the repeated empty output lines, -s
+	SqueezeBlank bool
+	// ShowEnds displays $ at the end of each line, -E
+	ShowEnds bool
+	// ShowTabs displays the TAB characters as ^I, -T
+	ShowTabs bool
+	// ShowNonPrinting uses the ^ and M- notation, except for LFD and TAB, -v
+	ShowNonPrinting bool
+	// ShowUnicode displays the invisible and misleading code points, e.g. the
+	// zero width and bidirectional controls, NO-BREAK SPACE and the homoglyphs
+	// of the Latin letters, as <U+XXXX>
+	ShowUnicode bool
+	// StripANSI removes the terminal escape sequences, e.g. the colours, the
+	// -v shows them as ^[[31m instead
+	StripANSI bool
+	// EOL converts the line terminators
+	EOL EOL
+	// Terminator ends the lines instead of the LF, e.g. the NUL of the records
+	// of -z. The CRLF and EOL are of the LF terminated lines only.
+	Terminator []byte
+	// Lines selects the lines to print as parsed by rangeutil.ParseRangeList,
+	// the numbers are then the line numbers of the input
+	Lines []rangeutil.Range
+}
+
+// Transforms reports whether any of the options changes the input
+func (o Options) Transforms() bool {
+	return o.Number || o.NumberNonBlank || o.SqueezeBlank || o.ShowEnds || o.ShowTabs || o.ShowNonPrinting ||
+		o.ShowUnicode || o.StripANSI || o.EOL != EOLKeep || o.Lines != nil
+}
+
+// EOL is the line terminator written for the input lines
+type EOL int
+
+const (
+	// EOLKeep writes the terminators of the input
+	EOLKeep EOL = iota
+	// EOLLF writes LF, the CR of a CRLF is dropped
+	EOLLF
+	// EOLCRLF writes CRLF, a CR is added ahead of a lone LF
+	EOLCRLF
+)
+
+var eolNames = map[string]EOL{
+	"keep": EOLKeep,
+	"lf":   EOLLF,
+	"crlf": EOLCRLF,
+}
+
+// ParseEOL maps a terminator name, case insensitive, to an EOL
+func ParseEOL(name string) (EOL, error) {
+	eol, ok := eolNames[strings.ToLower(name)]
+	if !ok {
+		return EOLKeep, fmt.Errorf("unsupported line terminator: %s", name)
+	}
+	return eol, nil
+}
+
+// Formatter formats text with the Options, it is not safe for concurrent use
+type Formatter struct {
+	opts Options
+	// terminator ends the lines, lf is set when it is the LF
+	terminator []byte
+	lf         bool
+
+	lineNumber  int
+	blanks      int
+	atLineStart bool
+	// skipLine drops the rest of a line that is not printed
+	skipLine bool
+
+	// inputLine is the number of the current input line and nextRange the
+	// index of the first of the Lines not yet passed
+	inputLine int
+	nextRange int
+
+	// pending is the end of a line cut short by the end of a write, held
+	// back until the next and written as is by the flush with final set
+	pending []byte
+	final   bool
+	// stripped is the line without the escape sequences
+	stripped []byte
+
+	// bidiControls is the number of the bidirectional controls shown
+	bidiControls int
+}
+
+func New(opts Options) *Formatter {
+	if opts.NumberNonBlank {
+		opts.Number = false
+	}
+	terminator := opts.Terminator
+	if len(terminator) == 0 {
+		terminator = []byte{'\n'}
+	}
+	return &Formatter{opts: opts, terminator: terminator, lf: bytes.Equal(terminator, []byte{'\n'}), atLineStart: true}
+}
+
+// Format copies r to w formatted and returns the number of bytes read from r,
+// the reading stops past the last of the selected Lines
+func (f *Formatter) Format(w io.Writer, r io.Reader) (int64, error) {
+	fw := f.Writer(w)
+	n, err := io.Copy(fw, r)
+	if err != nil && !errors.Is(err, ErrLinesDone) {
+		return n, err
+	}
+	return n, fw.Close()
+}
+
+// AtLineStart reports whether the text so far ends with a complete line
+func (f *Formatter) AtLineStart() bool {
+	return f.atLineStart
+}
+
+// EndLine ends the current line without any output, the next text starts a
+// new line, e.g. after a header written around the Formatter
+func (f *Formatter) EndLine() {
+	f.atLineStart = true
+}
+
+// BidiControls returns the number of the bidirectional control characters
+// found so far with ShowUnicode
+func (f *Formatter) BidiControls() int {
+	return f.bidiControls
+}
+
+// Done reports whether the input is past the last of the selected Lines, i.e.
+// the line ending the last of them has been read
+func (f *Formatter) Done() bool {
+	return f.opts.Lines != nil && f.atLineStart && f.inputLine >= f.opts.Lines[len(f.opts.Lines)-1].End
+}
+
+// Writer formats the text written to it and writes it to the underlying writer
+type Writer struct {
+	f   *Formatter
+	w   io.Writer
+	buf []byte
+}
+
+// Writer returns a Writer writing the formatted text to w
+func (f *Formatter) Writer(w io.Writer) *Writer {
+	return &Writer{f: f, w: w}
+}
+
+// Write formats p and writes it, the lines can be split across the writes.
+// Past the last of the selected Lines the rest of p is dropped and the error
+// is ErrLinesDone.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.buf = w.f.format(w.buf[:0], p)
+	if _, err := w.w.Write(w.buf); err != nil {
+		return 0, err
+	}
+	if w.f.Done() {
+		return len(p), ErrLinesDone
+	}
+	return len(p), nil
+}
+
+// Flush writes the text held back by the Writer, the start of a character cut
+// short by the end of the last write with ShowUnicode or a CR that might be
+// followed by a LF. It is written as is, the next write starts a new
+// character and line end.
+func (w *Writer) Flush() error {
+	w.buf = w.f.flush(w.buf[:0])
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+// Close writes any formatted text held back by the Writer, the underlying
+// writer is not closed
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+// Reader returns the formatted text read from the underlying reader
+type Reader struct {
+	f   *Formatter
+	r   io.Reader
+	in  []byte
+	out []byte
+	pos int
+	err error
+}
+
+const readerBufferSize = 32 * 1024
+
+// Reader returns a Reader of the text of r formatted
+func (f *Formatter) Reader(r io.Reader) *Reader {
+	return &Reader{f: f, r: r, in: make([]byte, readerBufferSize)}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for r.pos == len(r.out) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := r.r.Read(r.in)
+		r.out, r.pos = r.f.format(r.out[:0], r.in[:n]), 0
+		r.err = err
+		if err != nil {
+			r.out = r.f.flush(r.out)
+		}
+		if r.f.Done() {
+			r.err = io.EOF
+		}
+	}
+	n := copy(p, r.out[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+// format appends the formatted text of p to dst, p may end in the middle of a
+// line which is then continued by the next call
+func (f *Formatter) format(dst []byte, p []byte) []byte {
+	if len(f.pending) > 0 {
+		p = append(f.pending, p...)
+		f.pending = nil
+	}
+	for len(p) > 0 && !f.Done() {
+		line, rest, terminated := bytes.Cut(p, f.terminator)
+		// The rest of the line end comes with the next call
+		if n := f.heldBack(line); !terminated && n > 0 {
+			f.pending = append(f.pending, line[len(line)-n:]...)
+			if line = line[:len(line)-n]; len(line) == 0 {
+				break
+			}
+		}
+
+		if f.opts.StripANSI && bytes.IndexByte(line, esc) >= 0 {
+			f.stripped = stripANSI(f.stripped[:0], line)
+			line = f.stripped
+		}
+
+		crlf := f.lf && terminated && bytes.HasSuffix(line, []byte{'\r'})
+		if crlf {
+			line = line[:len(line)-1]
+		}
+		if f.atLineStart {
+			// The CR is not counted with the terminators converted
+			blank := terminated && (len(line) == 0 && (!crlf || f.opts.EOL != EOLKeep))
+			f.inputLine++
+			f.skipLine = !f.selectLine() || !f.startLine(&dst, blank)
+		}
+		f.atLineStart = terminated
+
+		if !f.skipLine {
+			dst = f.appendText(dst, line)
+			if terminated {
+				dst = f.appendLineEnd(dst, crlf)
+			}
+		}
+		p = rest
+	}
+	return dst
+}
+
+// heldBack returns the length of the end of a line that can not be formatted
+// before the rest of the line is known, a Terminator cut short, an escape
+// sequence cut short with StripANSI, a character cut short with ShowUnicode or
+// a CR which is a line end when followed by LF
+func (f *Formatter) heldBack(line []byte) int {
+	if f.final {
+		return 0
+	}
+	// The text ahead of a Terminator cut short can be cut short too
+	n := partialTerminator(line, f.terminator)
+	return n + f.heldBackText(line[:len(line)-n])
+}
+
+func (f *Formatter) heldBackText(line []byte) int {
+	if f.opts.StripANSI {
+		// A line of only escape sequences is blank, known once the line ends,
+		// the last of them can be cut short and a CR ending the line follow
+		if f.atLineStart && len(line) <= maxHeldSequence && onlySequences(line[:len(line)-f.heldBackCR(line)]) {
+			return len(line)
+		}
+		if n := partialSequence(line); n > 0 && n <= maxHeldSequence {
+			// The sequence can be all that comes between a CR and the LF
+			return n + f.heldBackCR(line[:len(line)-n])
+		}
+	}
+	if f.opts.ShowUnicode {
+		if n := partialRune(line); n > 0 {
+			return n
+		}
+	}
+	return f.heldBackCR(line)
+}
+
+// heldBackCR returns the length of the CR at the end of line, which is shown
+// or converted as a line end when followed by LF. With StripANSI the escape
+// sequences following it are held back too.
+func (f *Formatter) heldBackCR(line []byte) int {
+	if !f.lf || (!f.opts.ShowEnds && f.opts.EOL == EOLKeep) {
+		return 0
+	}
+	if f.opts.StripANSI {
+		if n := trailingCR(line); n <= maxHeldSequence {
+			return n
+		}
+		return 0
+	}
+	if bytes.HasSuffix(line, []byte{'\r'}) {
+		return 1
+	}
+	return 0
+}
+
+// partialTerminator returns the length of the end of line that is the start
+// of the terminator
+func partialTerminator(line []byte, terminator []byte) int {
+	for n := min(len(line), len(terminator)-1); n > 0; n-- {
+		if bytes.HasPrefix(terminator, line[len(line)-n:]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// appendLineEnd appends the terminator of a line, converted as set by EOL. Like
+// GNU cat the -E shows a CRLF as ^M$.
+func (f *Formatter) appendLineEnd(dst []byte, crlf bool) []byte {
+	if !f.lf {
+		if f.opts.ShowEnds {
+			dst = append(dst, '$')
+		}
+		return append(dst, f.terminator...)
+	}
+	switch f.opts.EOL {
+	case EOLLF:
+		crlf = false
+	case EOLCRLF:
+		crlf = true
+	}
+	switch {
+	case f.opts.ShowEnds && crlf:
+		dst = append(dst, '^', 'M', '$')
+	case f.opts.ShowEnds:
+		dst = append(dst, '$')
+	case crlf:
+		dst = f.appendBytes(dst, []byte{'\r'})
+	}
+	return append(dst, '\n')
+}
+
+// selectLine reports whether the current input line is one of the Lines
+func (f *Formatter) selectLine() bool {
+	if f.opts.Lines == nil {
+		return true
+	}
+	for f.nextRange < len(f.opts.Lines) && f.opts.Lines[f.nextRange].End < f.inputLine {
+		f.nextRange++
+	}
+	return f.nextRange < len(f.opts.Lines) && f.opts.Lines[f.nextRange].Start <= f.inputLine
+}
+
+// startLine handles the squeezing and numbering at the start of a line and
+// reports whether the line is printed. Like GNU cat the squeezed lines are not
+// numbered, -b prints the blank lines without a number and the numbers are
+// right aligned to 6 digits, growing wider past 999999.
+func (f *Formatter) startLine(dst *[]byte, blank bool) bool {
+	if blank {
+		f.blanks++
+		if f.opts.SqueezeBlank && f.blanks > 1 {
+			return false
+		}
+	} else {
+		f.blanks = 0
+	}
+
+	if f.opts.Number || (f.opts.NumberNonBlank && !blank) {
+		f.lineNumber++
+		if f.opts.Lines != nil {
+			f.lineNumber = f.inputLine
+		}
+		*dst = fmt.Appendf(*dst, "%6d\t", f.lineNumber)
+	}
+	return true
+}
+
+// flush appends the end of a line held back by format
+func (f *Formatter) flush(dst []byte) []byte {
+	if len(f.pending) == 0 {
+		return dst
+	}
+	p := f.pending
+	f.pending, f.final = nil, true
+	dst = f.format(dst, p)
+	f.final = false
+	return dst
+}
+
+// appendText appends the text of a line with the notations of the options
+func (f *Formatter) appendText(dst []byte, text []byte) []byte {
+	if f.opts.ShowUnicode {
+		return f.appendUnicode(dst, text)
+	}
+	return f.appendBytes(dst, text)
+}
+
+// appendBytes appends text with the -v and -T notations applied
+func (f *Formatter) appendBytes(dst []byte, text []byte) []byte {
+	if !f.opts.ShowNonPrinting && !f.opts.ShowTabs {
+		return append(dst, text...)
+	}
+	for _, c := range text {
+		switch {
+		case c == '\t' && f.opts.ShowTabs:
+			dst = append(dst, '^', 'I')
+		case f.opts.ShowNonPrinting:
+			dst = appendNonPrinting(dst, c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// appendNonPrinting appends c using the ^ and M- notation of GNU cat for the
+// control characters and the bytes above 127, the TAB and the LF within the
+// lines of another Terminator are kept as is
+func appendNonPrinting(dst []byte, c byte) []byte {
+	if c == '\t' || c == '\n' {
+		return append(dst, c)
+	}
+	if c >= 128 {
+		dst = append(dst, 'M', '-')
+		c -= 128
+	}
+	switch {
+	case c >= 32 && c < 127:
+		return append(dst, c)
+	case c == 127:
+		return append(dst, '^', '?')
+	default:
+		return append(dst, '^', c+64)
+	}
+}
@@ -0,0 +1,356 @@
+package format
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/enncod3/coding-challenges/cut/rangeutil"
+)
+
+func TestAppendNonPrinting(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{name: "printable ascii", input: []byte("line1 ~"), expected: "line1 ~"},
+		{name: "tab kept", input: []byte("a\tb"), expected: "a\tb"},
+		{name: "control characters", input: []byte{0, 1, '\r', 27, 31}, expected: "^@^A^M^[^_"},
+		{name: "delete", input: []byte{127}, expected: "^?"},
+		{name: "meta control characters", input: []byte{128, 137, 159}, expected: "M-^@M-^IM-^_"},
+		{name: "meta printable", input: []byte{160, 193, 254}, expected: "M- M-AM-~"},
+		{name: "meta delete", input: []byte{255}, expected: "M-^?"},
+		{name: "utf8", input: []byte("™"), expected: `M-bM-^DM-"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []byte
+			for _, c := range tt.input {
+				actual = appendNonPrinting(actual, c)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("appendNonPrinting() = %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	longLine := strings.Repeat("x", 200*1024)
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+	}{
+		{name: "no trailing newline", input: "line1\nline2", expected: "line1\nline2"},
+		{name: "crlf", input: "line1\r\nline2\r\n", expected: "line1\r\nline2\r\n"},
+		{name: "blank lines", input: "\n\nline3\n\n", expected: "\n\nline3\n\n"},
+		{name: "long line", input: longLine + "\n" + longLine, expected: longLine + "\n" + longLine},
+		{name: "binary", input: "\x00\x01\xff\xfe\n\x80", expected: "\x00\x01\xff\xfe\n\x80"},
+		{name: "empty", input: "", expected: ""},
+		{
+			name:     "long line formatted",
+			opts:     Options{Number: true, ShowEnds: true},
+			input:    longLine + "\nend",
+			expected: "     1\t" + longLine + "$\n     2\tend",
+		},
+		{
+			name:     "show all",
+			opts:     Options{ShowNonPrinting: true, ShowEnds: true, ShowTabs: true},
+			input:    "a\tb\x01\r\n\x89\n",
+			expected: "a^Ib^A^M$\nM-^I$\n",
+		},
+		{
+			name:     "number nonblank overrides number",
+			opts:     Options{Number: true, NumberNonBlank: true},
+			input:    "a\n\nb\n",
+			expected: "     1\ta\n\n     2\tb\n",
+		},
+		{
+			name:     "squeeze blank",
+			opts:     Options{SqueezeBlank: true, Number: true},
+			input:    "\n\n\na\n\n\n",
+			expected: "     1\t\n     2\ta\n     3\t\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual bytes.Buffer
+			if _, err := New(tt.opts).Format(&actual, strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Format() failed: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("Format() = %q, want %q", truncate(actual.String()), truncate(tt.expected))
+			}
+		})
+	}
+}
+
+// TestWriterSplitWrites checks that the lines split across the writes are the
+// same as when written at once
+func TestWriterSplitWrites(t *testing.T) {
+	opts := Options{Number: true, SqueezeBlank: true, ShowEnds: true, ShowNonPrinting: true}
+	input := "one\n\n\n\ttwo\x7f\nthree"
+
+	var expected bytes.Buffer
+	if _, err := New(opts).Format(&expected, strings.NewReader(input)); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	for size := 1; size < len(input); size++ {
+		var actual bytes.Buffer
+		w := New(opts).Writer(&actual)
+		for i := 0; i < len(input); i += size {
+			if _, err := w.Write([]byte(input[i:min(i+size, len(input))])); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		if actual.String() != expected.String() {
+			t.Errorf("writes of %d bytes = %q, want %q", size, actual.String(), expected.String())
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	f := New(Options{Number: true, ShowEnds: true})
+	actual, err := io.ReadAll(io.MultiReader(f.Reader(strings.NewReader("a\nb")), f.Reader(strings.NewReader("c\nd\n"))))
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	// The line continues into the next reader of the same Formatter
+	if expected := "     1\ta$\n     2\tbc$\n     3\td$\n"; string(actual) != expected {
+		t.Errorf("Reader() = %q, want %q", actual, expected)
+	}
+}
+
+func TestNumberWidthPastSixDigits(t *testing.T) {
+	f := New(Options{Number: true})
+	f.lineNumber = 999998
+
+	var actual bytes.Buffer
+	if _, err := f.Format(&actual, strings.NewReader("a\nb\n")); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	if expected := "999999\ta\n1000000\tb\n"; actual.String() != expected {
+		t.Errorf("Format() = %q, want %q", actual.String(), expected)
+	}
+}
+
+func truncate(s string) string {
+	if len(s) > 64 {
+		return s[:64] + "..."
+	}
+	return s
+}
+
+func TestLines(t *testing.T) {
+	input := "1\n2\n\n4\n5\n6\n7\n"
+	tests := []struct {
+		name     string
+		opts     Options
+		lines    string
+		expected string
+	}{
+		{name: "single line", lines: "2", expected: "2\n"},
+		{name: "list", lines: "1-2,5", expected: "1\n2\n5\n"},
+		{name: "open ended", lines: "6-", expected: "6\n7\n"},
+		{name: "past the input", lines: "7-20", expected: "7\n"},
+		{name: "original numbers", opts: Options{Number: true}, lines: "2-4,6",
+			expected: "     2\t2\n     3\t\n     4\t4\n     6\t6\n"},
+		{name: "original numbers of nonblank", opts: Options{NumberNonBlank: true}, lines: "3-4",
+			expected: "\n     4\t4\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges, err := rangeutil.ParseRangeList(tt.lines)
+			if err != nil {
+				t.Fatalf("Unable to parse %s, err: %s", tt.lines, err)
+			}
+			tt.opts.Lines = ranges
+
+			var actual bytes.Buffer
+			if _, err := New(tt.opts).Format(&actual, strings.NewReader(input)); err != nil {
+				t.Fatalf("Format() failed: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("Format() = %q, want %q", actual.String(), tt.expected)
+			}
+		})
+	}
+}
+
+// endless is a reader of numbered lines that never ends
+type endless struct {
+	line int
+}
+
+func (e *endless) Read(p []byte) (int, error) {
+	e.line++
+	return copy(p, fmt.Sprintf("line %d\n", e.line)), nil
+}
+
+func TestLinesStopReading(t *testing.T) {
+	ranges, err := rangeutil.ParseRangeList("2-3")
+	if err != nil {
+		t.Fatalf("Unable to parse ranges, err: %s", err)
+	}
+	f := New(Options{Lines: ranges})
+
+	input := &endless{}
+	actual, err := io.ReadAll(f.Reader(input))
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if expected := "line 2\nline 3\n"; string(actual) != expected {
+		t.Errorf("Reader() = %q, want %q", actual, expected)
+	}
+	if input.line != 3 {
+		t.Errorf("Reader() read %d lines, want 3", input.line)
+	}
+	if !f.Done() {
+		t.Errorf("Done() = false after the last selected line")
+	}
+
+	if _, err := f.Writer(io.Discard).Write([]byte("line 4\n")); !errors.Is(err, ErrLinesDone) {
+		t.Errorf("Write() after the last selected line error = %v, want ErrLinesDone", err)
+	}
+}
+
+func TestEOL(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+	}{
+		{name: "keep", input: "a\r\nb\n\r", expected: "a\r\nb\n\r"},
+		{name: "show ends of crlf like gnu", opts: Options{ShowEnds: true}, input: "a\r\nb\n\r\r\nc\r", expected: "a^M$\nb$\n\r^M$\nc\r"},
+		{name: "nonprinting keeps crlf as ^M", opts: Options{ShowNonPrinting: true}, input: "a\r\n", expected: "a^M\n"},
+		{name: "to lf", opts: Options{EOL: EOLLF}, input: "a\r\nb\n\r\r\nc\r", expected: "a\nb\n\r\nc\r"},
+		{name: "to crlf", opts: Options{EOL: EOLCRLF}, input: "a\r\nb\nc", expected: "a\r\nb\r\nc"},
+		{name: "to crlf shown", opts: Options{EOL: EOLCRLF, ShowEnds: true}, input: "a\r\nb\n", expected: "a^M$\nb^M$\n"},
+		{name: "to lf shown", opts: Options{EOL: EOLLF, ShowEnds: true}, input: "a\r\nb\n", expected: "a$\nb$\n"},
+		{name: "crlf line is not blank", opts: Options{NumberNonBlank: true}, input: "a\r\n\r\n", expected: "     1\ta\r\n     2\t\r\n"},
+		{
+			name:     "converted crlf line is blank",
+			opts:     Options{EOL: EOLLF, SqueezeBlank: true, NumberNonBlank: true},
+			input:    "a\r\n\r\n\r\n\nb\r\n",
+			expected: "     1\ta\n\n     2\tb\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual bytes.Buffer
+			if _, err := New(tt.opts).Format(&actual, strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Format() failed: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("Format() = %q, want %q", actual.String(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestEOLSplitWrites checks that a CRLF split across the writes is converted
+// as when written at once
+func TestEOLSplitWrites(t *testing.T) {
+	input := "\r\na\r\n\r\r\n\r\nb\rc\r"
+	for _, opts := range []Options{{ShowEnds: true, Number: true}, {EOL: EOLLF, SqueezeBlank: true}, {EOL: EOLCRLF, NumberNonBlank: true}} {
+		var expected bytes.Buffer
+		if _, err := New(opts).Format(&expected, strings.NewReader(input)); err != nil {
+			t.Fatalf("Format() failed: %v", err)
+		}
+
+		for size := 1; size < len(input); size++ {
+			var actual bytes.Buffer
+			w := New(opts).Writer(&actual)
+			for i := 0; i < len(input); i += size {
+				if _, err := w.Write([]byte(input[i:min(i+size, len(input))])); err != nil {
+					t.Fatalf("Write() failed: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+			if actual.String() != expected.String() {
+				t.Errorf("%+v writes of %d bytes = %q, want %q", opts, size, actual.String(), expected.String())
+			}
+		}
+	}
+}
+
+func TestParseEOL(t *testing.T) {
+	for name, expected := range map[string]EOL{"keep": EOLKeep, "LF": EOLLF, "crlf": EOLCRLF} {
+		if actual, err := ParseEOL(name); err != nil || actual != expected {
+			t.Errorf("ParseEOL(%q) = %v, %v, want %v", name, actual, err, expected)
+		}
+	}
+	if _, err := ParseEOL("cr"); err == nil {
+		t.Errorf("ParseEOL(%q) expected an error", "cr")
+	}
+}
+
+func TestTerminator(t *testing.T) {
+	nul := []byte{0}
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+	}{
+		{name: "numbered records", opts: Options{Terminator: nul, Number: true}, input: "a\nb\x00c\x00d", expected: "     1\ta\nb\x00     2\tc\x00     3\td"},
+		{name: "squeezed empty records", opts: Options{Terminator: nul, SqueezeBlank: true}, input: "a\x00\x00\x00\nb\x00", expected: "a\x00\x00\nb\x00"},
+		{name: "ends shown ahead of the terminator", opts: Options{Terminator: nul, ShowEnds: true}, input: "a\r\x00b\n\x00", expected: "a\r$\x00b\n$\x00"},
+		{name: "nonprinting keeps the lf", opts: Options{Terminator: nul, ShowNonPrinting: true}, input: "a\n\x01\x00", expected: "a\n^A\x00"},
+		{name: "multibyte terminator", opts: Options{Terminator: []byte("||"), NumberNonBlank: true}, input: "a|b||||c|", expected: "     1\ta|b||||     2\tc|"},
+		{name: "lf terminator", opts: Options{Terminator: []byte("\n"), ShowEnds: true}, input: "a\r\n", expected: "a^M$\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual bytes.Buffer
+			if _, err := New(tt.opts).Format(&actual, strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Format() failed: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("Format() = %q, want %q", actual.String(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestTerminatorSplitWrites checks that a terminator split across the writes
+// ends the line as when written at once
+func TestTerminatorSplitWrites(t *testing.T) {
+	input := "<>a<<>>\x1b[1m<>\x1b[0m<>é<>​<<"
+	opts := Options{Terminator: []byte("<>"), SqueezeBlank: true, NumberNonBlank: true, ShowEnds: true, StripANSI: true, ShowUnicode: true}
+	expected := "$<>     1\ta<$<>     2\t>$<>$<>     3\té$<>     4\t<U+200B><<"
+
+	for size := 1; size < len(input); size++ {
+		var actual bytes.Buffer
+		w := New(opts).Writer(&actual)
+		for i := 0; i < len(input); i += size {
+			if _, err := w.Write([]byte(input[i:min(i+size, len(input))])); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		if actual.String() != expected {
+			t.Errorf("writes of %d bytes = %q, want %q", size, actual.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// homoglyphs are the Cyrillic and Greek letters drawn like Latin ones, a word
+// mixing them with Latin letters reads the same but compares differently
+var homoglyphs = map[rune]bool{
+	// Cyrillic а е о р с у х і ј ѕ һ ԁ
+	0x0430: true, 0x0435: true, 0x043E: true, 0x0440: true, 0x0441: true, 0x0443: true,
+	0x0445: true, 0x0456: true, 0x0458: true, 0x0455: true, 0x04BB: true, 0x0501: true,
+	// Cyrillic А В Е К М Н О Р С Т Х І Ј Ѕ
+	0x0410: true, 0x0412: true, 0x0415: true, 0x041A: true, 0x041C: true, 0x041D: true, 0x041E: true,
+	0x0420: true, 0x0421: true, 0x0422: true, 0x0425: true, 0x0406: true, 0x0408: true, 0x0405: true,
+	// Greek ο ν and Α Β Ε Ζ Η Ι Κ Μ Ν Ο Ρ Τ Υ Χ
+	0x03BF: true, 0x03BD: true,
+	0x0391: true, 0x0392: true, 0x0395: true, 0x0396: true, 0x0397: true, 0x0399: true, 0x039A: true,
+	0x039C: true, 0x039D: true, 0x039F: true, 0x03A1: true, 0x03A4: true, 0x03A5: true, 0x03A7: true,
+}
+
+// revealed reports whether r is shown as <U+XXXX> by ShowUnicode: the format
+// characters such as the zero width ones and the bidirectional controls, the
+// variation selectors and other ignorable code points, the spaces other than
+// SPACE, e.g. NO-BREAK SPACE, the line and paragraph separators and the
+// homoglyphs of the Latin letters
+func revealed(r rune) bool {
+	if r < utf8.RuneSelf {
+		return false
+	}
+	return unicode.In(r, unicode.Cf, unicode.Zs, unicode.Zl, unicode.Zp,
+		unicode.Variation_Selector, unicode.Other_Default_Ignorable_Code_Point) || homoglyphs[r]
+}
+
+// isBidiControl reports whether r changes the direction of the text, the
+// characters of the Trojan Source attacks
+func isBidiControl(r rune) bool {
+	return unicode.Is(unicode.Bidi_Control, r)
+}
+
+// appendUnicode appends text with the revealed code points escaped and the
+// rest passed to appendBytes, the bidirectional controls are counted
+func (f *Formatter) appendUnicode(dst []byte, text []byte) []byte {
+	start := 0
+	for i := 0; i < len(text); {
+		if text[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(text[i:])
+		if !revealed(r) {
+			i += size
+			continue
+		}
+		if isBidiControl(r) {
+			f.bidiControls++
+		}
+		dst = f.appendBytes(dst, text[start:i])
+		dst = fmt.Appendf(dst, "<U+%04X>", r)
+		i += size
+		start = i
+	}
+	return f.appendBytes(dst, text[start:])
+}
+
+// partialRune returns the length of the UTF-8 sequence cut short at the end of
+// b, it is completed by the bytes of the next write
+func partialRune(b []byte) int {
+	for n := 1; n <= min(len(b), utf8.UTFMax-1); n++ {
+		if utf8.RuneStart(b[len(b)-n]) {
+			if utf8.FullRune(b[len(b)-n:]) {
+				return 0
+			}
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRevealed(t *testing.T) {
+	tests := []struct {
+		name     string
+		r        rune
+		expected bool
+	}{
+		{name: "ascii", r: 'a', expected: false},
+		{name: "space", r: ' ', expected: false},
+		{name: "accented letter", r: 'é', expected: false},
+		{name: "emoji", r: '🤦', expected: false},
+		{name: "zero width joiner", r: 0x200D, expected: true},
+		{name: "zero width space", r: 0x200B, expected: true},
+		{name: "right to left override", r: 0x202E, expected: true},
+		{name: "right to left isolate", r: 0x2067, expected: true},
+		{name: "no-break space", r: 0x00A0, expected: true},
+		{name: "soft hyphen", r: 0x00AD, expected: true},
+		{name: "byte order mark", r: 0xFEFF, expected: true},
+		{name: "variation selector", r: 0xFE0F, expected: true},
+		{name: "hangul filler", r: 0x3164, expected: true},
+		{name: "tag character", r: 0xE0041, expected: true},
+		{name: "cyrillic a", r: 'а', expected: true},
+		{name: "greek omicron", r: 'ο', expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := revealed(tt.r); actual != tt.expected {
+				t.Errorf("revealed(%U) = %v, want %v", tt.r, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShowUnicode(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+		bidi     int
+	}{
+		{name: "plain", input: "héllo wörld\n", expected: "héllo wörld\n"},
+		{name: "zwj sequence", input: "🤦🏼‍♂️\n", expected: "🤦🏼<U+200D>♂<U+FE0F>\n"},
+		{name: "bidi controls", input: "a‮b⁦c⁩\n", expected: "a<U+202E>b<U+2066>c<U+2069>\n", bidi: 3},
+		{name: "homoglyph", input: "pаypal\n", expected: "p<U+0430>ypal\n"},
+		{name: "invalid utf8 kept", input: "a\xe2\x80\n\xff", expected: "a\xe2\x80\n\xff"},
+		{
+			name:     "with nonprinting",
+			opts:     Options{ShowNonPrinting: true, ShowEnds: true},
+			input:    " é\r\n",
+			expected: "<U+00A0>M-CM-)^M$\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ShowUnicode = true
+			f := New(tt.opts)
+			var actual bytes.Buffer
+			if _, err := f.Format(&actual, strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Format() failed: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("Format() = %q, want %q", actual.String(), tt.expected)
+			}
+			if f.BidiControls() != tt.bidi {
+				t.Errorf("BidiControls() = %d, want %d", f.BidiControls(), tt.bidi)
+			}
+		})
+	}
+}
+
+// TestShowUnicodeSplitWrites checks that the characters split across the
+// writes are revealed as when written at once
+func TestShowUnicodeSplitWrites(t *testing.T) {
+	opts := Options{ShowUnicode: true, Number: true}
+	input := "‮ab \n🤦🏼‍♂️\n\xe2\x80"
+	expected := "     1\t<U+202E>ab<U+00A0>\n     2\t🤦🏼<U+200D>♂<U+FE0F>\n     3\t\xe2\x80"
+
+	for size := 1; size < len(input); size++ {
+		var actual bytes.Buffer
+		w := New(opts).Writer(&actual)
+		for i := 0; i < len(input); i += size {
+			if _, err := w.Write([]byte(input[i:min(i+size, len(input))])); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		if actual.String() != expected {
+			t.Errorf("writes of %d bytes = %q, want %q", size, actual.String(), expected)
+		}
+	}
+}
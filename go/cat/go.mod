@@ -3,14 +3,25 @@ module github.com/ennc0d3/coding-challenges/cat
 go 1.21
 
 require (
+	github.com/mattn/go-isatty v0.0.20
 	github.com/rs/zerolog v1.32.0
 	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/sys v0.16.0 // indirect
 )
+
+require (
+	github.com/ennc0d3/coding-challenges/wc v0.0.0
+	github.com/enncod3/coding-challenges/cut v0.0.0
+)
+
+// The --lines list is parsed by the rangeutil package of cut
+replace github.com/enncod3/coding-challenges/cut => ../cut
+
+// The encodings are named and detected by the charset package of wc
+replace github.com/ennc0d3/coding-challenges/wc => ../wc
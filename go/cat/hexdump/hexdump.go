@@ -0,0 +1,201 @@
+// Package hexdump renders bytes as the offset, hex and ASCII columns of xxd
+// and reads such a dump back into the bytes, like xxd -r.
+package hexdump
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSyntax is returned by the Reader for a line that is not part of a dump
+var ErrSyntax = errors.New("hexdump: invalid dump line")
+
+// Options are the layout of the dump, the zero value is the one of xxd
+type Options struct {
+	// Width is the number of bytes per line, 16 when zero
+	Width int
+	// Group is the number of bytes per group of hex digits, 2 when zero and
+	// a negative Group puts the whole line in one group like xxd -g 0
+	Group int
+}
+
+func (o Options) layout() (width, group int) {
+	width, group = o.Width, o.Group
+	if width <= 0 {
+		width = 16
+	}
+	switch {
+	case group == 0:
+		group = 2
+	case group < 0 || group > width:
+		group = width
+	}
+	return width, group
+}
+
+// Dumper writes the dump of the bytes written to it, a line is written once
+// its bytes are complete and the last one on Close
+type Dumper struct {
+	w            io.Writer
+	width, group int
+
+	offset int64
+	row    []byte
+	buf    []byte
+}
+
+// NewDumper returns a Dumper writing the dump to w
+func NewDumper(w io.Writer, opts Options) *Dumper {
+	width, group := opts.layout()
+	return &Dumper{w: w, width: width, group: group, row: make([]byte, 0, width)}
+}
+
+func (d *Dumper) Write(p []byte) (int, error) {
+	d.buf = d.buf[:0]
+	for n := 0; n < len(p); {
+		k := copy(d.row[len(d.row):d.width], p[n:])
+		d.row = d.row[:len(d.row)+k]
+		n += k
+		if len(d.row) == d.width {
+			d.buf = d.appendRow(d.buf)
+		}
+	}
+	if _, err := d.w.Write(d.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the last line when it is short, the underlying writer is not
+// closed
+func (d *Dumper) Close() error {
+	if len(d.row) == 0 {
+		return nil
+	}
+	d.buf = d.appendRow(d.buf[:0])
+	_, err := d.w.Write(d.buf)
+	return err
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendRow appends the line of the bytes in row, padded to the width
+func (d *Dumper) appendRow(dst []byte) []byte {
+	dst = fmt.Appendf(dst, "%08x: ", d.offset)
+	hexStart := len(dst)
+	for i, c := range d.row {
+		if i > 0 && i%d.group == 0 {
+			dst = append(dst, ' ')
+		}
+		dst = append(dst, hexDigits[c>>4], hexDigits[c&0x0f])
+	}
+	groups := (d.width + d.group - 1) / d.group
+	for len(dst)-hexStart < d.width*2+groups-1 {
+		dst = append(dst, ' ')
+	}
+	dst = append(dst, ' ', ' ')
+	for _, c := range d.row {
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		dst = append(dst, c)
+	}
+
+	d.offset += int64(len(d.row))
+	d.row = d.row[:0]
+	return append(dst, '\n')
+}
+
+// Reader returns the bytes of a dump read from the underlying reader, in any
+// width and grouping. A gap between the offsets of the lines is filled with
+// zeros as when xxd -r writes to a pipe.
+type Reader struct {
+	r      *bufio.Reader
+	line   int
+	offset int64
+	// gap is the number of zeros read ahead of out, for the offsets skipped
+	gap int64
+	out []byte
+	pos int
+	err error
+}
+
+// NewReader returns a Reader of the bytes dumped in r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for r.gap == 0 && r.pos == len(r.out) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		line, err := r.r.ReadBytes('\n')
+		r.out, r.pos = r.out[:0], 0
+		if len(bytes.TrimSpace(line)) > 0 {
+			r.line++
+			r.out, r.err = r.parseLine(r.out, line)
+		}
+		if err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	// The gap is read in the size of p rather than held in memory
+	if r.gap > 0 {
+		n := int(min(int64(len(p)), r.gap))
+		clear(p[:n])
+		r.gap -= int64(n)
+		return n, nil
+	}
+	n := copy(p, r.out[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+// parseLine appends the bytes of a "OFFSET: HEX  ASCII" line to dst, the hex
+// column ends at two spaces or at the end of the line. The offsets skipped
+// since the last line are added to the gap.
+func (r *Reader) parseLine(dst []byte, line []byte) ([]byte, error) {
+	offsetText, data, found := bytes.Cut(line, []byte{':'})
+	var offset int64
+	if _, err := fmt.Sscanf(string(bytes.TrimSpace(offsetText)), "%x", &offset); !found || err != nil {
+		return dst, fmt.Errorf("%w %d: no offset", ErrSyntax, r.line)
+	}
+	if offset < r.offset {
+		return dst, fmt.Errorf("%w %d: offset %x is before %x", ErrSyntax, r.line, offset, r.offset)
+	}
+	r.gap, r.offset = offset-r.offset, offset
+
+	data = bytes.TrimRight(data, "\r\n")
+	for i := 0; i < len(data); {
+		if data[i] == ' ' {
+			if i > 0 && data[i-1] == ' ' {
+				break
+			}
+			i++
+			continue
+		}
+		if i+1 >= len(data) || unhex(data[i]) < 0 || unhex(data[i+1]) < 0 {
+			return dst, fmt.Errorf("%w %d: invalid hex %q", ErrSyntax, r.line, data[i:min(i+2, len(data))])
+		}
+		dst = append(dst, byte(unhex(data[i])<<4|unhex(data[i+1])))
+		r.offset++
+		i += 2
+	}
+	return dst, nil
+}
+
+func unhex(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c - 'a' + 10)
+	case c >= 'A' && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
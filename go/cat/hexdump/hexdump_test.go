@@ -0,0 +1,161 @@
+package hexdump
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDumper(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+	}{
+		{name: "empty", input: "", expected: ""},
+		{
+			name:     "short line",
+			input:    "hi",
+			expected: "00000000: 6869                                     hi\n",
+		},
+		{
+			name:  "nonprinting as dots",
+			input: "line\x00\x7f\xff\n0123456789",
+			expected: "00000000: 6c69 6e65 007f ff0a 3031 3233 3435 3637  line....01234567\n" +
+				"00000010: 3839                                     89\n",
+		},
+		{
+			name:  "width and group",
+			opts:  Options{Width: 10, Group: 4},
+			input: "hello world, this is more\x00\xff",
+			expected: "00000000: 68656c6c 6f20776f 726c  hello worl\n" +
+				"0000000a: 642c2074 68697320 6973  d, this is\n" +
+				"00000014: 206d6f72 6500ff          more..\n",
+		},
+		{
+			name:     "group not dividing the width",
+			opts:     Options{Group: 3},
+			input:    "abcdefghijklmnopq",
+			expected: "00000000: 616263 646566 676869 6a6b6c 6d6e6f 70  abcdefghijklmnop\n00000010: 71                                     q\n",
+		},
+		{
+			name:     "no grouping",
+			opts:     Options{Width: 4, Group: -1},
+			input:    "hi!",
+			expected: "00000000: 686921    hi!\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual bytes.Buffer
+			d := NewDumper(&actual, tt.opts)
+			if _, err := d.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+			if err := d.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("Dumper() = %q, want %q", actual.String(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestDumperSplitWrites checks that the lines split across the writes are the
+// same as when written at once
+func TestDumperSplitWrites(t *testing.T) {
+	input := strings.Repeat("0123456789abcdef\x00", 5)
+	var expected bytes.Buffer
+	d := NewDumper(&expected, Options{})
+	_, _ = d.Write([]byte(input))
+	_ = d.Close()
+
+	for size := 1; size < len(input); size++ {
+		var actual bytes.Buffer
+		d := NewDumper(&actual, Options{})
+		for i := 0; i < len(input); i += size {
+			if _, err := d.Write([]byte(input[i:min(i+size, len(input))])); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+		}
+		if err := d.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		if actual.String() != expected.String() {
+			t.Errorf("writes of %d bytes = %q, want %q", size, actual.String(), expected.String())
+		}
+	}
+}
+
+func TestReaderReversesDumper(t *testing.T) {
+	input := "\x00\x01 binary\xff\xfe\n  two  spaces  \n" + strings.Repeat("x", 100)
+	for _, opts := range []Options{{}, {Width: 1}, {Width: 7, Group: 1}, {Width: 10, Group: 4}, {Width: 32, Group: -1}} {
+		var dump bytes.Buffer
+		d := NewDumper(&dump, opts)
+		_, _ = d.Write([]byte(input))
+		_ = d.Close()
+
+		actual, err := io.ReadAll(NewReader(&dump))
+		if err != nil {
+			t.Fatalf("Reader(%+v) failed: %v", opts, err)
+		}
+		if string(actual) != input {
+			t.Errorf("Reader(%+v) = %q, want %q", opts, actual, input)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{name: "upper case without ascii", input: "0: 4142 43\n", expected: "ABC"},
+		{name: "crlf and blank lines", input: "00000000: 6869  hi\r\n\r\n00000002: 21  !\r\n", expected: "hi!"},
+		{name: "gap filled with zeros", input: "00000000: 41  A\n00000004: 42  B\n", expected: "A\x00\x00\x00B"},
+		{name: "offset going back", input: "00000004: 41  A\n00000000: 42  B\n", expected: "\x00\x00\x00\x00A", err: true},
+		{name: "no offset", input: "00000000: 41  A\nhello\n", expected: "A", err: true},
+		{name: "invalid hex", input: "00000000: 4x41  .A\n", err: true},
+		{name: "odd digits", input: "00000000: 414\n", expected: "A", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := io.ReadAll(NewReader(strings.NewReader(tt.input)))
+			if string(actual) != tt.expected {
+				t.Errorf("Reader(%q) = %q, want %q", tt.input, actual, tt.expected)
+			}
+			if (err != nil) != tt.err || (err != nil && !errors.Is(err, ErrSyntax)) {
+				t.Errorf("Reader(%q) error = %v, want error %v", tt.input, err, tt.err)
+			}
+		})
+	}
+}
+
+// TestReaderLargeGap checks that a far offset is read as zeros without
+// holding the whole gap in memory
+func TestReaderLargeGap(t *testing.T) {
+	r := NewReader(strings.NewReader("7fffffff: 41  A\n"))
+	head := make([]byte, 1<<20)
+	if _, err := io.ReadFull(r, head); err != nil {
+		t.Fatalf("Reader failed: %v", err)
+	}
+	if !bytes.Equal(head, make([]byte, len(head))) {
+		t.Errorf("Reader gap is not zeros")
+	}
+	if cap(r.out) > 1<<16 {
+		t.Errorf("Reader holds %d bytes for the gap", cap(r.out))
+	}
+
+	n, err := io.Copy(io.Discard, io.LimitReader(r, 1<<40))
+	if err != nil || int64(len(head))+n != 0x7fffffff+1 {
+		t.Errorf("Reader read %d bytes, %v, want %d", int64(len(head))+n, err, 0x7fffffff+1)
+	}
+}
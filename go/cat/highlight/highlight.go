@@ -0,0 +1,204 @@
+// Package highlight colours the keywords, strings, comments and numbers of
+// source code with the ANSI escapes. The colours are reset at the end of each
+// line and the empty lines are left empty, so the lines can be numbered and
+// squeezed like the plain text.
+package highlight
+
+import (
+	"bytes"
+	"io"
+)
+
+// The ANSI escapes of the token colours
+const (
+	keywordColour = "\x1b[34m"
+	stringColour  = "\x1b[32m"
+	commentColour = "\x1b[36m"
+	numberColour  = "\x1b[33m"
+	reset         = "\x1b[0m"
+)
+
+// Writer colours the text written to it line by line and writes it to the
+// underlying writer, the comments and strings can span the lines
+type Writer struct {
+	w    io.Writer
+	lang *Language
+
+	// line is the start of a line split across the writes and open the
+	// comment or string continued on the next line
+	line []byte
+	open *delimiters
+	buf  []byte
+}
+
+// NewWriter returns a Writer colouring the text of lang and writing it to w
+func NewWriter(w io.Writer, lang *Language) *Writer {
+	return &Writer{w: w, lang: lang}
+}
+
+// Write colours the complete lines of p, the rest of p is held back until the
+// line ends or the Writer is closed
+func (h *Writer) Write(p []byte) (int, error) {
+	h.buf = h.buf[:0]
+	for rest := p; len(rest) > 0; {
+		line, after, hasNewline := bytes.Cut(rest, []byte{'\n'})
+		if !hasNewline {
+			h.line = append(h.line, line...)
+			break
+		}
+		if len(h.line) > 0 {
+			h.line = append(h.line, line...)
+			line = h.line
+		}
+		h.buf = append(h.highlight(h.buf, line), '\n')
+		h.line = h.line[:0]
+		rest = after
+	}
+	if _, err := h.w.Write(h.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the last line when it has no newline, the underlying writer is
+// not closed
+func (h *Writer) Close() error {
+	if len(h.line) == 0 {
+		return nil
+	}
+	h.buf = h.highlight(h.buf[:0], h.line)
+	h.line = h.line[:0]
+	_, err := h.w.Write(h.buf)
+	return err
+}
+
+// highlight appends the coloured line, without its newline, to dst
+func (h *Writer) highlight(dst []byte, line []byte) []byte {
+	i := 0
+	if h.open != nil {
+		end, closed := h.open.end(line, 0)
+		dst = appendColoured(dst, h.colour(h.open), line[:end])
+		if closed {
+			h.open = nil
+		}
+		i = end
+	}
+
+	for i < len(line) {
+		start := i
+		c := line[i]
+		afterSpace := i == 0 || isSpace(line[i-1])
+		valueStart := afterSpace || bytes.IndexByte([]byte("[{,:"), line[i-1]) >= 0
+
+		if (afterSpace || !h.lang.commentAfterSpace) && hasAnyPrefix(line[i:], h.lang.lineComments) {
+			return appendColoured(dst, commentColour, line[i:])
+		}
+		if d := h.opening(line[i:], valueStart || !h.lang.quoteAfterSpace); d != nil {
+			end, closed := d.end(line, i+len(d.open))
+			if !closed && d.multiline {
+				h.open = d
+			}
+			dst = appendColoured(dst, h.colour(d), line[start:end])
+			i = end
+			continue
+		}
+
+		switch {
+		case isDigit(c):
+			for i < len(line) && (isWordByte(line[i]) || line[i] == '.') {
+				i++
+			}
+			dst = appendColoured(dst, numberColour, line[start:i])
+		case isWordByte(c):
+			for i < len(line) && isWordByte(line[i]) {
+				i++
+			}
+			if h.lang.keywords[string(line[start:i])] {
+				dst = appendColoured(dst, keywordColour, line[start:i])
+			} else {
+				dst = append(dst, line[start:i]...)
+			}
+		default:
+			dst = append(dst, c)
+			i++
+		}
+	}
+	return dst
+}
+
+// opening returns the block comment or string starting text, the longest
+// opening is listed first, e.g. the """ ahead of the "
+func (h *Writer) opening(text []byte, quoted bool) *delimiters {
+	for i := range h.lang.blockComments {
+		if d := &h.lang.blockComments[i]; bytes.HasPrefix(text, []byte(d.open)) {
+			return d
+		}
+	}
+	if !quoted {
+		return nil
+	}
+	for i := range h.lang.strings {
+		if d := &h.lang.strings[i]; bytes.HasPrefix(text, []byte(d.open)) {
+			return d
+		}
+	}
+	return nil
+}
+
+// colour returns the colour of the block comments and strings
+func (h *Writer) colour(d *delimiters) string {
+	for i := range h.lang.blockComments {
+		if d == &h.lang.blockComments[i] {
+			return commentColour
+		}
+	}
+	return stringColour
+}
+
+// end returns the offset just after the closing of d in line from i, or the
+// length of line when it is not closed on the line
+func (d *delimiters) end(line []byte, i int) (int, bool) {
+	for i < len(line) {
+		if d.escape && line[i] == '\\' {
+			i += 2
+			continue
+		}
+		if bytes.HasPrefix(line[i:], []byte(d.close)) {
+			return i + len(d.close), true
+		}
+		i++
+	}
+	return len(line), false
+}
+
+// appendColoured appends the text in the colour, the empty text is appended
+// without any escapes
+func appendColoured(dst []byte, colour string, text []byte) []byte {
+	if len(text) == 0 {
+		return dst
+	}
+	dst = append(dst, colour...)
+	dst = append(dst, text...)
+	return append(dst, reset...)
+}
+
+func hasAnyPrefix(text []byte, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(text, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
@@ -0,0 +1,140 @@
+package highlight
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// tagged replaces the colours with tags that read better in the tests
+var tagged = strings.NewReplacer(
+	keywordColour, "<k>",
+	stringColour, "<s>",
+	commentColour, "<c>",
+	numberColour, "<n>",
+	reset, "</>",
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		head     string
+		expected *Language
+	}{
+		{name: "go extension", file: "main.go", expected: Go},
+		{name: "python extension", file: "dir/script.py", expected: Python},
+		{name: "upper case extension", file: "CONFIG.YML", expected: YAML},
+		{name: "json extension", file: "package.json", expected: JSON},
+		{name: "extension ahead of shebang", file: "run.sh", head: "#!/usr/bin/python3\n", expected: Shell},
+		{name: "shebang", file: "run", head: "#!/bin/bash\necho\n", expected: Shell},
+		{name: "env shebang with version", file: "-", head: "#!/usr/bin/env python3.11\n", expected: Python},
+		{name: "env shebang with flags", file: "-", head: "#!/usr/bin/env -S bash -e\n", expected: Shell},
+		{name: "unknown shebang", file: "run", head: "#!/usr/bin/perl\n", expected: nil},
+		{name: "no shebang", file: "README", head: "# Title\n", expected: nil},
+		{name: "empty", file: "-", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := Detect(tt.file, []byte(tt.head)); actual != tt.expected {
+				t.Errorf("Detect(%q, %q) = %v, want %v", tt.file, tt.head, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     *Language
+		input    string
+		expected string
+	}{
+		{
+			name:     "go",
+			lang:     Go,
+			input:    "func main() {\n\tx := \"a\\\"b\" // c\n\treturn 42, nil\n}\n",
+			expected: "<k>func</> main() {\n\tx := <s>\"a\\\"b\"</> <c>// c</>\n\t<k>return</> <n>42</>, <k>nil</>\n}\n",
+		},
+		{
+			name:     "go block comment and raw string across lines",
+			lang:     Go,
+			input:    "/* a\n\nb */ x := `c\nd` // e",
+			expected: "<c>/* a</>\n\n<c>b */</> x := <s>`c</>\n<s>d`</> <c>// e</>",
+		},
+		{
+			name:     "python",
+			lang:     Python,
+			input:    "def f(x):\n    return x * 1.5  # half again\ns = '''a\nb''' if True else None\n",
+			expected: "<k>def</> f(x):\n    <k>return</> x * <n>1.5</>  <c># half again</>\ns = <s>'''a</>\n<s>b'''</> <k>if</> <k>True</> <k>else</> <k>None</>\n",
+		},
+		{
+			name:     "shell",
+			lang:     Shell,
+			input:    "if [ ${#a} -gt 0 ]; then # count\n  echo \"$a\" 'b'\nfi\n",
+			expected: "<k>if</> [ ${#a} -gt <n>0</> ]; <k>then</> <c># count</>\n  echo <s>\"$a\"</> <s>'b'</>\n<k>fi</>\n",
+		},
+		{
+			name:     "json",
+			lang:     JSON,
+			input:    "{\"a\": [1, true, null, \"b\\\\\"]}\n",
+			expected: "{<s>\"a\"</>: [<n>1</>, <k>true</>, <k>null</>, <s>\"b\\\\\"</>]}\n",
+		},
+		{
+			name:     "yaml",
+			lang:     YAML,
+			input:    "key: don't # note\nlist: ['a', \"b\", yes]\nport: 8080\n",
+			expected: "key: don't <c># note</>\nlist: [<s>'a'</>, <s>\"b\"</>, <k>yes</>]\nport: <n>8080</>\n",
+		},
+		{
+			name:     "words containing keywords and digits",
+			lang:     Go,
+			input:    "format x1 if_2\n",
+			expected: "format x1 if_2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual bytes.Buffer
+			w := NewWriter(&actual, tt.lang)
+			if _, err := w.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+			if tagged := tagged.Replace(actual.String()); tagged != tt.expected {
+				t.Errorf("Writer(%s) = %q, want %q", tt.lang.Name, tagged, tt.expected)
+			}
+		})
+	}
+}
+
+// TestWriterSplitWrites checks that the lines split across the writes are
+// coloured as when written at once
+func TestWriterSplitWrites(t *testing.T) {
+	input := "package main\n\n/* a\nb */\nvar s = `x\ny` // z\nvar n = 10"
+
+	var expected bytes.Buffer
+	w := NewWriter(&expected, Go)
+	_, _ = w.Write([]byte(input))
+	_ = w.Close()
+
+	for size := 1; size < len(input); size++ {
+		var actual bytes.Buffer
+		w := NewWriter(&actual, Go)
+		for i := 0; i < len(input); i += size {
+			if _, err := w.Write([]byte(input[i:min(i+size, len(input))])); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		if actual.String() != expected.String() {
+			t.Errorf("writes of %d bytes = %q, want %q", size, actual.String(), expected.String())
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package highlight
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Language describes the tokens of a language, only as much as needed to
+// colour the keywords, strings, comments and numbers
+type Language struct {
+	Name string
+
+	keywords      map[string]bool
+	lineComments  []string
+	blockComments []delimiters
+	strings       []delimiters
+	// commentAfterSpace starts the line comments only at the start of a word
+	// and quoteAfterSpace the strings only at the start of a value, as the #
+	// of the shell and the quotes of YAML
+	commentAfterSpace bool
+	quoteAfterSpace   bool
+}
+
+// delimiters are the opening and closing of a block comment or string
+type delimiters struct {
+	open, close string
+	// escape allows a backslash to escape the closing
+	escape bool
+	// multiline allows the token to continue on the next lines
+	multiline bool
+}
+
+func words(list string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	Go = &Language{
+		Name: "go",
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if import
+			interface map package range return select struct switch type var true false nil iota`),
+		lineComments:  []string{"//"},
+		blockComments: []delimiters{{open: "/*", close: "*/", multiline: true}},
+		strings: []delimiters{
+			{open: `"`, close: `"`, escape: true},
+			{open: "'", close: "'", escape: true},
+			{open: "`", close: "`", multiline: true},
+		},
+	}
+
+	Python = &Language{
+		Name: "python",
+		keywords: words(`False None True and as assert async await break class continue def del elif else except
+			finally for from global if import in is lambda nonlocal not or pass raise return try while with yield`),
+		lineComments: []string{"#"},
+		strings: []delimiters{
+			{open: `"""`, close: `"""`, escape: true, multiline: true},
+			{open: "'''", close: "'''", escape: true, multiline: true},
+			{open: `"`, close: `"`, escape: true},
+			{open: "'", close: "'", escape: true},
+		},
+	}
+
+	Shell = &Language{
+		Name: "shell",
+		keywords: words(`if then else elif fi for while until do done case esac in function select time return
+			break continue local export readonly declare unset shift exit`),
+		lineComments: []string{"#"},
+		strings: []delimiters{
+			{open: `"`, close: `"`, escape: true, multiline: true},
+			{open: "'", close: "'", multiline: true},
+		},
+		commentAfterSpace: true,
+	}
+
+	JSON = &Language{
+		Name:     "json",
+		keywords: words("true false null"),
+		strings:  []delimiters{{open: `"`, close: `"`, escape: true}},
+	}
+
+	YAML = &Language{
+		Name:         "yaml",
+		keywords:     words("true false null yes no on off True False Null Yes No On Off TRUE FALSE NULL"),
+		lineComments: []string{"#"},
+		strings: []delimiters{
+			{open: `"`, close: `"`, escape: true, multiline: true},
+			{open: "'", close: "'", multiline: true},
+		},
+		commentAfterSpace: true,
+		quoteAfterSpace:   true,
+	}
+)
+
+var extensions = map[string]*Language{
+	".go":   Go,
+	".py":   Python,
+	".pyw":  Python,
+	".sh":   Shell,
+	".bash": Shell,
+	".zsh":  Shell,
+	".ksh":  Shell,
+	".json": JSON,
+	".yaml": YAML,
+	".yml":  YAML,
+}
+
+var interpreters = map[string]*Language{
+	"python": Python,
+	"sh":     Shell,
+	"bash":   Shell,
+	"dash":   Shell,
+	"zsh":    Shell,
+	"ksh":    Shell,
+}
+
+// Detect returns the language of the file from its extension, or else from
+// the interpreter of the #! line at the start of head, nil when unknown
+func Detect(name string, head []byte) *Language {
+	if lang, ok := extensions[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang
+	}
+	return shebang(head)
+}
+
+// shebang returns the language of the interpreter of a "#!/bin/sh" or a
+// "#!/usr/bin/env python3" line, the version is ignored
+func shebang(head []byte) *Language {
+	line, _, _ := bytes.Cut(head, []byte{'\n'})
+	if !bytes.HasPrefix(line, []byte("#!")) {
+		return nil
+	}
+	args := strings.Fields(string(line[2:]))
+	for len(args) > 0 && (filepath.Base(args[0]) == "env" || strings.HasPrefix(args[0], "-")) {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	interpreter := strings.TrimRight(filepath.Base(args[0]), "0123456789.")
+	return interpreters[interpreter]
+}
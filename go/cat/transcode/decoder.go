@@ -0,0 +1,182 @@
+package transcode
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+)
+
+const decoderBufferSize = 32 * 1024
+
+// replacement is U+FFFD in UTF-8
+var replacement = []byte("�")
+
+// Decoder reads the text of the underlying reader as UTF-8
+type Decoder struct {
+	r    io.Reader
+	opts Options
+	enc  charset.Encoding
+
+	// in is the input not decoded yet, started once the BOM is handled and
+	// offset the position of in in the input
+	in      []byte
+	started bool
+	offset  int64
+
+	out []byte
+	pos int
+	err error
+}
+
+// NewDecoder returns a Decoder of the text of r in the Encoding of opts
+func NewDecoder(r io.Reader, opts Options) *Decoder {
+	return &Decoder{r: r, opts: opts, in: make([]byte, 0, decoderBufferSize)}
+}
+
+func (d *Decoder) Read(p []byte) (int, error) {
+	for d.pos == len(d.out) {
+		if d.err != nil {
+			return 0, d.err
+		}
+		n, err := d.r.Read(d.in[len(d.in):cap(d.in)])
+		d.in = d.in[:len(d.in)+n]
+		atEOF := err != nil
+		d.out, d.pos = d.out[:0], 0
+
+		if !d.started {
+			// The BOM is at most 3 bytes, a shorter input has no BOM to wait for
+			if len(d.in) < charset.MaxBOMLen && !atEOF {
+				continue
+			}
+			d.start()
+		}
+
+		var used int
+		d.out, used, d.err = d.decode(d.out, d.in, atEOF)
+		d.offset += int64(used)
+		d.in = d.in[:copy(d.in, d.in[used:])]
+		if err != nil && d.err == nil {
+			d.err = err
+		}
+	}
+	n := copy(p, d.out[d.pos:])
+	d.pos += n
+	return n, nil
+}
+
+// start settles the encoding and handles the BOM at the start of the input
+func (d *Decoder) start() {
+	d.started = true
+	detected, bomLen := charset.Detect(d.in)
+	d.enc = d.opts.Encoding
+	if d.enc == charset.Auto {
+		d.enc = detected
+	}
+	if bomLen == 0 || detected != d.enc {
+		return
+	}
+	if !d.opts.StripBOM {
+		d.out = append(d.out, "\ufeff"...)
+	}
+	d.in = d.in[:copy(d.in, d.in[bomLen:])]
+	d.offset += int64(bomLen)
+}
+
+// decode appends the UTF-8 of in to dst and returns the number of bytes of in
+// used, a character cut short is left for the next call unless at EOF
+func (d *Decoder) decode(dst []byte, in []byte, atEOF bool) ([]byte, int, error) {
+	switch d.enc {
+	case charset.Latin1:
+		for _, c := range in {
+			dst = utf8.AppendRune(dst, rune(c))
+		}
+		return dst, len(in), nil
+	case charset.UTF16LE, charset.UTF16BE:
+		return d.decodeUTF16(dst, in, atEOF)
+	}
+	return d.decodeUTF8(dst, in, atEOF)
+}
+
+func (d *Decoder) decodeUTF8(dst []byte, in []byte, atEOF bool) ([]byte, int, error) {
+	var err error
+	i := 0
+	for i < len(in) {
+		if c := in[i]; c < utf8.RuneSelf {
+			dst = append(dst, c)
+			i++
+			continue
+		}
+		if !utf8.FullRune(in[i:]) && !atEOF {
+			break
+		}
+		r, size := utf8.DecodeRune(in[i:])
+		if r == utf8.RuneError && size == 1 {
+			if dst, err = d.invalid(dst, in[i:i+1], i); err != nil {
+				return dst, i, err
+			}
+		} else {
+			dst = append(dst, in[i:i+size]...)
+		}
+		i += size
+	}
+	return dst, i, nil
+}
+
+func (d *Decoder) decodeUTF16(dst []byte, in []byte, atEOF bool) ([]byte, int, error) {
+	unit := func(i int) rune {
+		if d.enc == charset.UTF16BE {
+			return rune(in[i])<<8 | rune(in[i+1])
+		}
+		return rune(in[i+1])<<8 | rune(in[i])
+	}
+
+	var err error
+	i := 0
+	for i < len(in) {
+		if len(in)-i < 2 {
+			if !atEOF {
+				break
+			}
+			// A dangling odd byte can not be a complete code unit
+			dst, err = d.invalid(dst, in[i:], i)
+			return dst, len(in), err
+		}
+
+		r, size := unit(i), 2
+		if utf16.IsSurrogate(r) {
+			// The high surrogate is followed by the low one
+			if r < 0xDC00 && len(in)-i < 4 && !atEOF {
+				break
+			}
+			if r < 0xDC00 && len(in)-i >= 4 {
+				if pair := utf16.DecodeRune(r, unit(i+2)); pair != utf8.RuneError {
+					r, size = pair, 4
+				}
+			}
+			if size == 2 {
+				if dst, err = d.invalid(dst, in[i:i+2], i); err != nil {
+					return dst, i, err
+				}
+				i += size
+				continue
+			}
+		}
+		dst = utf8.AppendRune(dst, r)
+		i += size
+	}
+	return dst, i, nil
+}
+
+// invalid handles the invalid bytes of the input at i as set by OnInvalid
+func (d *Decoder) invalid(dst []byte, raw []byte, i int) ([]byte, error) {
+	switch d.opts.OnInvalid {
+	case InvalidFail:
+		return dst, fmt.Errorf("%w %s at byte %d", ErrInvalid, d.enc, d.offset+int64(i))
+	case InvalidPassthrough:
+		return append(dst, raw...), nil
+	}
+	return append(dst, replacement...), nil
+}
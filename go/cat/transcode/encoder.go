@@ -0,0 +1,135 @@
+package transcode
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+)
+
+// Encoder writes the UTF-8 text written to it in its Encoding to the
+// underlying writer. Latin-1 has no BOM, U+FEFF is left out of it.
+type Encoder struct {
+	w    io.Writer
+	opts Options
+
+	// pending is the start of a character cut short by the end of a write
+	pending []byte
+	buf     []byte
+	offset  int64
+}
+
+// NewEncoder returns an Encoder writing to w in the Encoding of opts
+func NewEncoder(w io.Writer, opts Options) *Encoder {
+	return &Encoder{w: w, opts: opts}
+}
+
+func (e *Encoder) Write(p []byte) (int, error) {
+	if err := e.write(p, false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the character cut short at the end as invalid input, the
+// underlying writer is not closed
+func (e *Encoder) Close() error {
+	return e.write(nil, true)
+}
+
+func (e *Encoder) write(p []byte, atEOF bool) error {
+	data := p
+	if len(e.pending) > 0 {
+		data = append(e.pending, p...)
+		e.pending = nil
+	}
+	var used int
+	var err error
+	e.buf, used, err = e.encode(e.buf[:0], data, atEOF)
+	e.offset += int64(used)
+	if err == nil {
+		e.pending = append(e.pending, data[used:]...)
+	}
+	if _, werr := e.w.Write(e.buf); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// encode appends the text of the UTF-8 in to dst and returns the number of
+// bytes of in used, a character cut short is left for the next call unless at
+// EOF
+func (e *Encoder) encode(dst []byte, in []byte, atEOF bool) ([]byte, int, error) {
+	var err error
+	i := 0
+	for i < len(in) {
+		r, size := rune(in[i]), 1
+		if r >= utf8.RuneSelf {
+			if !utf8.FullRune(in[i:]) && !atEOF {
+				break
+			}
+			r, size = utf8.DecodeRune(in[i:])
+		}
+
+		if r == utf8.RuneError && size == 1 {
+			dst, err = e.invalid(dst, in[i:i+1], fmt.Errorf("%w UTF-8 at byte %d", ErrInvalid, e.offset+int64(i)))
+		} else {
+			dst, err = e.appendRune(dst, r, in[i:i+size])
+		}
+		if err != nil {
+			return dst, i, err
+		}
+		i += size
+	}
+	return dst, i, nil
+}
+
+// appendRune appends r in the Encoding, raw is its UTF-8
+func (e *Encoder) appendRune(dst []byte, r rune, raw []byte) ([]byte, error) {
+	switch e.opts.Encoding {
+	case charset.Latin1:
+		switch {
+		case r == 0xFEFF:
+			return dst, nil
+		case r <= 0xFF:
+			return append(dst, byte(r)), nil
+		}
+		return e.invalid(dst, raw, fmt.Errorf("%w U+%04X in %s", ErrUnencodable, r, e.opts.Encoding))
+	case charset.UTF16LE, charset.UTF16BE:
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			return e.appendUnit(e.appendUnit(dst, r1), r2), nil
+		}
+		return e.appendUnit(dst, r), nil
+	}
+	return append(dst, raw...), nil
+}
+
+func (e *Encoder) appendUnit(dst []byte, r rune) []byte {
+	if e.opts.Encoding == charset.UTF16BE {
+		return append(dst, byte(r>>8), byte(r))
+	}
+	return append(dst, byte(r), byte(r>>8))
+}
+
+// invalid handles the invalid or unencodable raw bytes as set by OnInvalid,
+// err is the error to fail with
+func (e *Encoder) invalid(dst []byte, raw []byte, err error) ([]byte, error) {
+	switch e.opts.OnInvalid {
+	case InvalidFail:
+		return dst, err
+	case InvalidPassthrough:
+		// A byte would break the alignment of the UTF-16 code units, it is
+		// replaced instead
+		if e.opts.Encoding != charset.UTF16LE && e.opts.Encoding != charset.UTF16BE {
+			return append(dst, raw...), nil
+		}
+	case InvalidReplace:
+		if e.opts.Encoding == charset.Latin1 {
+			return append(dst, '?'), nil
+		}
+	}
+	return e.appendRune(dst, utf8.RuneError, replacement)
+}
@@ -0,0 +1,63 @@
+// Package transcode converts streams of text between UTF-8, UTF-16 and
+// Latin-1, the encodings named by the charset package of wc. A Decoder reads
+// the text of any of them as UTF-8 and an Encoder writes UTF-8 in any of
+// them, so the text can be processed as UTF-8 in between. The invalid input
+// is handled as set by the Invalid policy.
+package transcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+)
+
+// Invalid is the policy for the invalid input and for the characters the
+// Encoder can not encode
+type Invalid int
+
+const (
+	// InvalidReplace replaces them with U+FFFD, or with ? in Latin-1
+	InvalidReplace Invalid = iota
+	// InvalidFail stops with ErrInvalid or ErrUnencodable
+	InvalidFail
+	// InvalidPassthrough copies their bytes unchanged, the Encoder replaces
+	// them in UTF-16
+	InvalidPassthrough
+)
+
+var invalidNames = map[string]Invalid{
+	"replace":     InvalidReplace,
+	"fail":        InvalidFail,
+	"passthrough": InvalidPassthrough,
+}
+
+// ParseInvalid maps a policy name, case insensitive, to an Invalid
+func ParseInvalid(name string) (Invalid, error) {
+	policy, ok := invalidNames[strings.ToLower(name)]
+	if !ok {
+		return InvalidReplace, fmt.Errorf("unsupported invalid input policy: %s", name)
+	}
+	return policy, nil
+}
+
+var (
+	// ErrInvalid is returned for the invalid input with InvalidFail
+	ErrInvalid = errors.New("transcode: invalid")
+	// ErrUnencodable is returned for a character not in the encoding of the
+	// Encoder with InvalidFail
+	ErrUnencodable = errors.New("transcode: can not encode")
+)
+
+// Options are the options of a Decoder or Encoder
+type Options struct {
+	// Encoding is the encoding read by a Decoder or written by an Encoder,
+	// charset.Auto detects the BOM for a Decoder and is UTF-8 for an Encoder
+	Encoding charset.Encoding
+	// StripBOM drops the BOM at the start of the input of a Decoder, it is
+	// otherwise read as U+FEFF
+	StripBOM bool
+	// OnInvalid is the policy for the invalid input
+	OnInvalid Invalid
+}
@@ -0,0 +1,135 @@
+package transcode
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+)
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+		err      error
+	}{
+		{name: "utf8", input: "héllo\n", expected: "héllo\n"},
+		{name: "utf8 bom kept", input: "\xef\xbb\xbfhi", expected: "\xef\xbb\xbfhi"},
+		{name: "utf8 bom stripped", opts: Options{StripBOM: true}, input: "\xef\xbb\xbfhi", expected: "hi"},
+		{name: "utf16le detected", opts: Options{StripBOM: true}, input: "\xff\xfeh\x00\xe9\x00", expected: "hé"},
+		{name: "utf16be detected", input: "\xfe\xff\x00h\xd8\x3d\xde\x00", expected: "\ufeffh😀"},
+		{name: "utf16le without bom", opts: Options{Encoding: charset.UTF16LE}, input: "h\x00i\x00", expected: "hi"},
+		{name: "bom of another encoding", opts: Options{Encoding: charset.Latin1, StripBOM: true}, input: "\xff\xfe", expected: "ÿþ"},
+		{name: "latin1", opts: Options{Encoding: charset.Latin1}, input: "caf\xe9", expected: "café"},
+		{name: "short input", input: "a", expected: "a"},
+		{name: "empty", input: "", expected: ""},
+		{name: "invalid utf8 replaced", input: "a\xffb\xe2\x82", expected: "a�b��"},
+		{name: "invalid utf8 passed through", opts: Options{OnInvalid: InvalidPassthrough}, input: "a\xffb", expected: "a\xffb"},
+		{name: "invalid utf8 fails", opts: Options{OnInvalid: InvalidFail}, input: "ab\xffc", expected: "ab", err: ErrInvalid},
+		{name: "odd utf16 byte", opts: Options{Encoding: charset.UTF16LE}, input: "h\x00i", expected: "h�"},
+		{name: "lone surrogates", opts: Options{Encoding: charset.UTF16LE}, input: "\x3d\xd8h\x00\x00\xde", expected: "�h�"},
+		{
+			name:     "lone surrogate passed through",
+			opts:     Options{Encoding: charset.UTF16BE, OnInvalid: InvalidPassthrough},
+			input:    "\xd8\x3d\x00h",
+			expected: "\xd8\x3dh",
+		},
+		{name: "lone surrogate fails", opts: Options{Encoding: charset.UTF16BE, OnInvalid: InvalidFail}, input: "\x00h\xd8\x3d", expected: "h", err: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One byte at a time the characters are split across the reads
+			for _, r := range []io.Reader{strings.NewReader(tt.input), iotest.OneByteReader(strings.NewReader(tt.input))} {
+				actual, err := io.ReadAll(NewDecoder(r, tt.opts))
+				if string(actual) != tt.expected {
+					t.Errorf("Decoder(%q) = %q, want %q", tt.input, actual, tt.expected)
+				}
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Decoder(%q) error = %v, want %v", tt.input, err, tt.err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderErrorOffset(t *testing.T) {
+	_, err := io.ReadAll(NewDecoder(strings.NewReader("\xef\xbb\xbfab\xff"), Options{OnInvalid: InvalidFail}))
+	if expected := "transcode: invalid UTF-8 at byte 5"; err == nil || err.Error() != expected {
+		t.Errorf("Decoder() error = %v, want %s", err, expected)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		input    string
+		expected string
+		err      error
+	}{
+		{name: "utf8 validated", opts: Options{Encoding: charset.UTF8}, input: "hé\xff", expected: "hé�"},
+		{name: "utf16le", opts: Options{Encoding: charset.UTF16LE}, input: "\ufeffhé😀", expected: "\xff\xfeh\x00\xe9\x00\x3d\xd8\x00\xde"},
+		{name: "utf16be", opts: Options{Encoding: charset.UTF16BE}, input: "hé", expected: "\x00h\x00\xe9"},
+		{name: "latin1", opts: Options{Encoding: charset.Latin1}, input: "\ufeffcafé", expected: "caf\xe9"},
+		{name: "latin1 replaced", opts: Options{Encoding: charset.Latin1}, input: "a€\xff", expected: "a??"},
+		{name: "latin1 passed through", opts: Options{Encoding: charset.Latin1, OnInvalid: InvalidPassthrough}, input: "a€", expected: "a€"},
+		{name: "latin1 fails", opts: Options{Encoding: charset.Latin1, OnInvalid: InvalidFail}, input: "ab€", expected: "ab", err: ErrUnencodable},
+		{name: "invalid utf8 replaced", opts: Options{Encoding: charset.UTF16LE}, input: "a\xff", expected: "a\x00\xfd\xff"},
+		{name: "invalid utf8 fails", opts: Options{Encoding: charset.UTF16LE, OnInvalid: InvalidFail}, input: "a\xff", expected: "a\x00", err: ErrInvalid},
+		{name: "invalid utf8 replaced in utf16", opts: Options{Encoding: charset.UTF16LE, OnInvalid: InvalidPassthrough}, input: "a\xffb", expected: "a\x00\xfd\xffb\x00"},
+		{name: "cut short at the end", opts: Options{Encoding: charset.UTF16BE}, input: "a\xe2\x82", expected: "\x00a\xff\xfd\xff\xfd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One byte at a time the characters are split across the writes
+			for _, size := range []int{len(tt.input), 1} {
+				var actual bytes.Buffer
+				e := NewEncoder(&actual, tt.opts)
+				var err error
+				for i := 0; i < len(tt.input) && err == nil; i += size {
+					_, err = e.Write([]byte(tt.input[i:min(i+size, len(tt.input))]))
+				}
+				if err == nil {
+					err = e.Close()
+				}
+				if actual.String() != tt.expected {
+					t.Errorf("Encoder(%q) = %q, want %q", tt.input, actual.String(), tt.expected)
+				}
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Encoder(%q) error = %v, want %v", tt.input, err, tt.err)
+				}
+			}
+		})
+	}
+}
+
+// TestRoundTrip converts the text to each encoding and back
+func TestRoundTrip(t *testing.T) {
+	const text = "\ufeffname,city\r\nJosé,Zürich\r\n"
+	for _, enc := range []charset.Encoding{charset.UTF8, charset.UTF16LE, charset.UTF16BE, charset.Latin1} {
+		var encoded bytes.Buffer
+		e := NewEncoder(&encoded, Options{Encoding: enc})
+		if _, err := e.Write([]byte(text)); err != nil {
+			t.Fatalf("Encoder(%s) failed: %v", enc, err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("Encoder(%s) failed: %v", enc, err)
+		}
+
+		decoded, err := io.ReadAll(NewDecoder(&encoded, Options{Encoding: enc, StripBOM: true}))
+		if err != nil {
+			t.Fatalf("Decoder(%s) failed: %v", enc, err)
+		}
+		if expected := strings.TrimPrefix(text, "\ufeff"); string(decoded) != expected {
+			t.Errorf("round trip of %s = %q, want %q", enc, decoded, expected)
+		}
+	}
+}
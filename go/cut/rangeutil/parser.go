@@ -13,6 +13,38 @@ type Range struct {
 }
 
 func ParseRangeList(rangeList string) ([]Range, error) {
+	parsedRanges, err := parseRanges(rangeList)
+	if err != nil {
+		return nil, err
+	}
+	return mergeRanges(parsedRanges), nil
+}
+
+// ParsePositionList parses a list of 1-based positions, e.g. the lines or the
+// bytes of a file, with the syntax of ParseRangeList. The positions start at 1
+// and a range can not be decreasing. An empty list selects all the positions
+// and returns nil.
+func ParsePositionList(rangeList string) ([]Range, error) {
+	if rangeList == "" {
+		return nil, nil
+	}
+	parsedRanges, err := parseRanges(rangeList)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range parsedRanges {
+		if r.Start < 1 {
+			return nil, fmt.Errorf("positions are numbered from 1")
+		}
+		if r.Start > r.End {
+			return nil, fmt.Errorf("invalid decreasing range: %d-%d", r.Start, r.End)
+		}
+	}
+	return mergeRanges(parsedRanges), nil
+}
+
+// parseRanges parses the ranges of the list in their order
+func parseRanges(rangeList string) ([]Range, error) {
 	ranges := strings.Split(rangeList, ",")
 	parsedRanges := make([]Range, 0, len(ranges))
 
@@ -57,7 +89,11 @@ func ParseRangeList(rangeList string) ([]Range, error) {
 		}
 
 	}
+	return parsedRanges, nil
+}
 
+// mergeRanges sorts the ranges and merges the overlapping ones
+func mergeRanges(parsedRanges []Range) []Range {
 	// Sort the ranges by start value
 	sort.Slice(parsedRanges, func(i, j int) bool {
 		return parsedRanges[i].Start < parsedRanges[j].Start
@@ -83,7 +119,7 @@ func ParseRangeList(rangeList string) ([]Range, error) {
 	// Add the last range
 	mergedRanges = append(mergedRanges, currentRange)
 
-	return mergedRanges, nil
+	return mergedRanges
 }
 
 func ComplementRangeList(rangeList []Range, max int) ([]Range, error) {
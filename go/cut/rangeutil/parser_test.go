@@ -69,6 +69,54 @@ func TestParseRangeList(t *testing.T) {
 	}
 }
 
+func TestParsePositionList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Range
+		wantErr bool
+	}{
+		{
+			name:  "empty list",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "merged ranges",
+			input: "5-,1-3,2-4",
+			want:  []Range{{Start: 1, End: 4}, {Start: 5, End: int(^uint(0) >> 1)}},
+		},
+		{
+			name:    "position zero",
+			input:   "0-3",
+			wantErr: true,
+		},
+		{
+			name:    "decreasing range",
+			input:   "5-3",
+			wantErr: true,
+		},
+		{
+			name:    "decreasing range within another",
+			input:   "1-10,5-3",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePositionList(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePositionList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePositionList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestComplementRangeList(t *testing.T) {
 	tests := []struct {
 		name       string
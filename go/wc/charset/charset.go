@@ -0,0 +1,80 @@
+// Package charset names the text encodings read by wc and cat and detects
+// them from the byte order mark at the start of the text.
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Encoding is the encoding of the text
+type Encoding int
+
+const (
+	// Auto detects the encoding from the BOM, defaulting to UTF-8
+	Auto Encoding = iota
+	UTF8
+	UTF16LE
+	UTF16BE
+	Latin1
+)
+
+// MaxBOMLen is the length of the longest BOM, the one of UTF-8
+const MaxBOMLen = 3
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+var names = map[string]Encoding{
+	"auto":       Auto,
+	"utf-8":      UTF8,
+	"utf8":       UTF8,
+	"utf-16le":   UTF16LE,
+	"utf16le":    UTF16LE,
+	"utf-16be":   UTF16BE,
+	"utf16be":    UTF16BE,
+	"latin-1":    Latin1,
+	"latin1":     Latin1,
+	"iso-8859-1": Latin1,
+}
+
+// Parse maps an encoding name, case insensitive, to an Encoding
+func Parse(name string) (Encoding, error) {
+	enc, ok := names[strings.ToLower(name)]
+	if !ok {
+		return Auto, fmt.Errorf("unsupported encoding: %s", name)
+	}
+	return enc, nil
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case UTF8:
+		return "UTF-8"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case Latin1:
+		return "Latin-1"
+	}
+	return "auto"
+}
+
+// Detect returns the encoding given by the BOM at the start of data and the
+// length of the BOM. Data without a BOM is treated as UTF-8.
+func Detect(data []byte) (Encoding, int) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return UTF8, len(bomUTF8)
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return UTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return UTF16BE, len(bomUTF16BE)
+	}
+	return UTF8, 0
+}
@@ -0,0 +1,46 @@
+package charset
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for name, expected := range map[string]Encoding{
+		"auto":       Auto,
+		"UTF-8":      UTF8,
+		"utf-16le":   UTF16LE,
+		"UTF16BE":    UTF16BE,
+		"latin-1":    Latin1,
+		"ISO-8859-1": Latin1,
+	} {
+		got, err := Parse(name)
+		if err != nil || got != expected {
+			t.Errorf("Parse(%q) = %v, %v, want %v", name, got, err, expected)
+		}
+	}
+
+	if _, err := Parse("ebcdic"); err == nil {
+		t.Errorf("Parse(\"ebcdic\") expected an error")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testcases := map[string]struct {
+		data     string
+		expected Encoding
+		bomLen   int
+	}{
+		"UTF8BOM":    {"\xEF\xBB\xBFa", UTF8, 3},
+		"UTF16LEBOM": {"\xFF\xFEa\x00", UTF16LE, 2},
+		"UTF16BEBOM": {"\xFE\xFF\x00a", UTF16BE, 2},
+		"NoBOM":      {"abc", UTF8, 0},
+		"ShortInput": {"\xEF\xBB", UTF8, 0},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			got, bomLen := Detect([]byte(tc.data))
+			if got != tc.expected || bomLen != tc.bomLen {
+				t.Errorf("Detect(%q) = %v, %d, want %v, %d", tc.data, got, bomLen, tc.expected, tc.bomLen)
+			}
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
 
+	"github.com/ennc0d3/coding-challenges/wc/charset"
 	"github.com/ennc0d3/coding-challenges/wc/util"
+	"github.com/enncod3/coding-challenges/cut/rangeutil"
 	"github.com/spf13/cobra"
 )
 
@@ -14,19 +18,55 @@ var rootCmd = &cobra.Command{
 	Long: `Print newline, word, and byte counts for each FILE, and a total line if
 more than one FILE is specified.`,
 	Args: cobra.ArbitraryArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		encoding, err = charset.Parse(EncodingFlag)
+		if err != nil {
+			return err
+		}
+
+		if LinesRangeFlag != "" && BytesRangeFlag != "" {
+			return errors.New("only one of --lines-range or --bytes-range may be specified")
+		}
+		if linesRange, err = rangeutil.ParsePositionList(LinesRangeFlag); err != nil {
+			return fmt.Errorf("invalid range list %q: %w", LinesRangeFlag, err)
+		}
+		if bytesRange, err = rangeutil.ParsePositionList(BytesRangeFlag); err != nil {
+			return fmt.Errorf("invalid range list %q: %w", BytesRangeFlag, err)
+		}
+
+		patterns = make([]*regexp.Regexp, 0, len(CountMatchingFlag))
+		for _, expr := range CountMatchingFlag {
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", expr, err)
+			}
+			patterns = append(patterns, pattern)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 
 		fileNames := args
 		if len(args) < 1 {
 			fileNames = []string{os.Stdin.Name()}
 		}
-		stats := util.ProcessFiles(fileNames)
+		stats := util.ProcessFiles(fileNames, util.ProcessOptions{
+			Encoding:     encoding,
+			LinesRange:   linesRange,
+			BytesRange:   bytesRange,
+			Patterns:     patterns,
+			CountMatches: CountMatchesFlag,
+			Fast:         fastCount(),
+			Mmap:         MmapFlag})
 
 		opts := util.PrintOptions{
-			Lines: LineFlag,
-			Words: WordFlag,
-			Bytes: ByteFlag,
-			Chars: CharFlag}
+			Lines:    LineFlag,
+			Words:    WordFlag,
+			Bytes:    ByteFlag,
+			Chars:    CharFlag,
+			Patterns: len(patterns),
+			Matches:  CountMatchesFlag}
 		util.PrintStats(stats, opts)
 	},
 }
@@ -37,6 +77,30 @@ var CharFlag bool
 var Version bool
 var LineFlag bool
 var WordFlag bool
+var EncodingFlag string
+var LinesRangeFlag string
+var BytesRangeFlag string
+var CountMatchingFlag []string
+var CountMatchesFlag bool
+var MmapFlag bool
+
+var encoding charset.Encoding
+var linesRange []rangeutil.Range
+var bytesRange []rangeutil.Range
+var patterns []*regexp.Regexp
+
+// fastCount selects the fast path when none of the counts needs decoding
+func fastCount() util.FastCount {
+	switch {
+	case WordFlag || CharFlag:
+		return util.FastCountNone
+	case LineFlag:
+		return util.FastCountLines
+	case ByteFlag:
+		return util.FastCountBytes
+	}
+	return util.FastCountNone
+}
 
 func init() {
 
@@ -47,6 +111,12 @@ func init() {
 	rootCmd.Flags().BoolVarP(&CharFlag, "chars", "m", false, "char count output")
 	rootCmd.Flags().BoolVarP(&LineFlag, "lines", "l", false, "line count output")
 	rootCmd.Flags().BoolVarP(&WordFlag, "words", "w", false, "word count output")
+	rootCmd.Flags().StringVar(&LinesRangeFlag, "lines-range", "", "count only these lines, e.g. 1-10,50,100-")
+	rootCmd.Flags().StringVar(&BytesRangeFlag, "bytes-range", "", "count only these bytes, e.g. 1-512")
+	rootCmd.Flags().StringArrayVar(&CountMatchingFlag, "count-matching", nil, "count the lines matching REGEX, can be repeated")
+	rootCmd.Flags().BoolVar(&CountMatchesFlag, "count-matches", false, "also count the total matches of each --count-matching REGEX")
+	rootCmd.Flags().BoolVar(&MmapFlag, "mmap", false, "map regular files into memory instead of reading them")
+	rootCmd.Flags().StringVar(&EncodingFlag, "encoding", "auto", "input encoding: auto, utf-8, utf-16le, utf-16be or latin-1")
 
 }
 
@@ -11,3 +11,8 @@ require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 )
+
+require github.com/enncod3/coding-challenges/cut v0.0.0
+
+// rangeutil is shared with the cut module in this repository
+replace github.com/enncod3/coding-challenges/cut => ../cut
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+)
+
+// DecodeToUTF8 converts data in the given encoding to UTF-8, the BOM if present
+// is dropped so that it is not counted as a character.
+func DecodeToUTF8(data []byte, enc charset.Encoding) []byte {
+	detected, bomLen := charset.Detect(data)
+	if enc == charset.Auto {
+		enc = detected
+	}
+	if enc == detected {
+		data = data[bomLen:]
+	}
+
+	switch enc {
+	case charset.UTF16LE, charset.UTF16BE:
+		return decodeUTF16(data, enc == charset.UTF16BE)
+	case charset.Latin1:
+		return decodeLatin1(data)
+	}
+	return data
+}
+
+func decodeUTF16(data []byte, bigEndian bool) []byte {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+
+	decoded := make([]byte, 0, len(data))
+	for _, r := range utf16.Decode(units) {
+		decoded = utf8.AppendRune(decoded, r)
+	}
+	// A dangling odd byte can not be a complete code unit
+	if len(data)%2 != 0 {
+		decoded = utf8.AppendRune(decoded, utf8.RuneError)
+	}
+	return decoded
+}
+
+func decodeLatin1(data []byte) []byte {
+	decoded := make([]byte, 0, len(data))
+	for _, b := range data {
+		decoded = utf8.AppendRune(decoded, rune(b))
+	}
+	return decoded
+}
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeToUTF8(t *testing.T) {
+	testcases := map[string]struct {
+		input    []byte
+		encoding charset.Encoding
+		expected string
+	}{
+		"NoBOMDefaultsToUTF8": {
+			[]byte("Gutenberg™\n"),
+			charset.Auto,
+			"Gutenberg™\n",
+		},
+		"UTF8BOMStripped": {
+			[]byte("\xEF\xBB\xBFOne\n"),
+			charset.Auto,
+			"One\n",
+		},
+		"UTF16LEBOM": {
+			[]byte{0xFF, 0xFE, 'a', 0, ' ', 0, 'b', 0, '\n', 0},
+			charset.Auto,
+			"a b\n",
+		},
+		"UTF16BEBOM": {
+			[]byte{0xFE, 0xFF, 0, 'a', 0, ' ', 0, 'b', 0, '\n'},
+			charset.Auto,
+			"a b\n",
+		},
+		"UTF16LESurrogatePair": {
+			[]byte{0xFF, 0xFE, 0x3E, 0xD8, 0x26, 0xDD},
+			charset.Auto,
+			"🤦",
+		},
+		"UTF16LEForcedWithoutBOM": {
+			[]byte{'a', 0, '\n', 0},
+			charset.UTF16LE,
+			"a\n",
+		},
+		"UTF16LEOddTrailingByte": {
+			[]byte{0xFF, 0xFE, 'a', 0, 'b'},
+			charset.Auto,
+			"a�",
+		},
+		"Latin1Forced": {
+			[]byte{'c', 'a', 'f', 0xE9},
+			charset.Latin1,
+			"café",
+		},
+		"UTF8ForcedKeepsForeignBOM": {
+			[]byte{0xFF, 0xFE, 'a'},
+			charset.UTF8,
+			"\xFF\xFEa",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			got := string(DecodeToUTF8(tc.input, tc.encoding))
+			if d := cmp.Diff(tc.expected, got); d != "" {
+				t.Errorf("Decoded text differs (-want vs +got): %s\n", d)
+			}
+		})
+	}
+}
+
+func TestProcessFileEncoding(t *testing.T) {
+	testcases := map[string]struct {
+		inputFileData []byte
+		encoding      charset.Encoding
+		expFileStat   FileStat
+	}{
+		"UTF16LEWithBOM": {
+			[]byte{0xFF, 0xFE, 'O', 0, 'n', 0, 'e', 0, ' ', 0, 'T', 0, 'w', 0, 'o', 0, '\r', 0, '\n', 0},
+			charset.Auto,
+			FileStat{"words": 2, "lines": 1, "bytes": 20, "chars": 9},
+		},
+		"UTF16BEWithBOM": {
+			[]byte{0xFE, 0xFF, 0, 'O', 0, 'n', 0, 'e', 0, '\n'},
+			charset.Auto,
+			FileStat{"words": 1, "lines": 1, "bytes": 10, "chars": 4},
+		},
+		"UTF8WithBOM": {
+			[]byte("\xEF\xBB\xBFGutenberg™\n"),
+			charset.Auto,
+			FileStat{"words": 1, "lines": 1, "bytes": 16, "chars": 11},
+		},
+		"Latin1": {
+			[]byte{'c', 'a', 'f', 0xE9, '\n'},
+			charset.Latin1,
+			FileStat{"words": 1, "lines": 1, "bytes": 5, "chars": 5},
+		},
+	}
+
+	tmpDir, err := os.MkdirTemp("/tmp/", "wc-go*")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory, err: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			fp, err := os.CreateTemp(tmpDir, "*.txt")
+			if err != nil {
+				t.Fatalf("Unable to create tempfile err: %s", err)
+			}
+			if err := os.WriteFile(fp.Name(), tc.inputFileData, fs.FileMode(os.O_WRONLY)); err != nil {
+				t.Fatalf("Unable to write %s, err: %s", fp.Name(), err)
+			}
+			got := ProcessFiles([]string{fp.Name()}, ProcessOptions{Encoding: tc.encoding})
+			if d := cmp.Diff(tc.expFileStat, got[fp.Name()]); d != "" {
+				t.Errorf("FileStat Differs (-want vs +got): %s\n", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+)
+
+// FastCount selects a specialised path when only the lines and/or bytes are
+// printed, the input is then neither decoded nor split into words
+type FastCount int
+
+const (
+	FastCountNone FastCount = iota
+	// FastCountBytes counts only the bytes, taken from stat for regular files
+	FastCountBytes
+	// FastCountLines counts only the lines and the bytes
+	FastCountLines
+)
+
+const fastCountBufferSize = 128 * 1024
+
+// fastCountFile counts the lines and bytes of the file without decoding it,
+// input that needs decoding to find the newlines, i.e. UTF-16, falls back to
+// the full count
+func fastCountFile(fileName string, processOptions ProcessOptions) (FileStat, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if processOptions.Fast == FastCountBytes {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			return FileStat{"bytes": int(info.Size())}, nil
+		}
+	}
+
+	if processOptions.Mmap {
+		if data, err := mmapFile(f); err == nil {
+			defer func() { _ = munmapFile(data) }()
+			return fastCountData(data, processOptions), nil
+		}
+	}
+
+	reader := bufio.NewReaderSize(f, fastCountBufferSize)
+	encoding := processOptions.Encoding
+	if encoding == charset.Auto {
+		// The BOM is at most 3 bytes, a shorter input has no BOM to peek
+		bom, _ := reader.Peek(charset.MaxBOMLen)
+		encoding, _ = charset.Detect(bom)
+	}
+	if encoding == charset.UTF16LE || encoding == charset.UTF16BE {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		return countData(data, encoding, processOptions), nil
+	}
+
+	return countLines(reader)
+}
+
+// fastCountData is the fastCountFile for input already in memory
+func fastCountData(data []byte, processOptions ProcessOptions) FileStat {
+	encoding := processOptions.Encoding
+	if encoding == charset.Auto {
+		encoding, _ = charset.Detect(data)
+	}
+	if encoding == charset.UTF16LE || encoding == charset.UTF16BE {
+		return countData(data, encoding, processOptions)
+	}
+	return FileStat{"bytes": len(data), "lines": bytes.Count(data, []byte{'\n'})}
+}
+
+// countLines counts the newlines of r in large chunks, bytes.Count uses the
+// vectorised byte search of the platform
+func countLines(r io.Reader) (FileStat, error) {
+	buf := make([]byte, fastCountBufferSize)
+	lines, size := 0, 0
+	for {
+		n, err := r.Read(buf)
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		size += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return FileStat{"bytes": size, "lines": lines}, nil
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFastCount(t *testing.T) {
+	testcases := map[string]struct {
+		inputFileData []byte
+		fast          FastCount
+		expFileStat   FileStat
+	}{
+		"LinesAndBytes": {
+			[]byte("TwoLines\n\n"),
+			FastCountLines,
+			FileStat{"lines": 2, "bytes": 10},
+		},
+		"NoLFInLastLine": {
+			[]byte("Gutenberg™\nGustavberg"),
+			FastCountLines,
+			FileStat{"lines": 1, "bytes": 23},
+		},
+		"BytesFromStat": {
+			[]byte("🤦🏼‍♂️"),
+			FastCountBytes,
+			FileStat{"bytes": 17},
+		},
+		"EmptyFile": {
+			[]byte{},
+			FastCountLines,
+			FileStat{"lines": 0, "bytes": 0},
+		},
+		"UTF16LEFallsBackToFullCount": {
+			// U+0A0A contains newline bytes but is not a newline
+			[]byte{0xFF, 0xFE, 0x0A, 0x0A, '\n', 0},
+			FastCountLines,
+			FileStat{"lines": 1, "bytes": 6, "words": 1, "chars": 2},
+		},
+	}
+
+	tmpDir := t.TempDir()
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			fileName := filepath.Join(tmpDir, name+".txt")
+			if err := os.WriteFile(fileName, tc.inputFileData, fs.FileMode(0o600)); err != nil {
+				t.Fatalf("Unable to write %s, err: %s", fileName, err)
+			}
+			got := ProcessFiles([]string{fileName}, ProcessOptions{Fast: tc.fast})
+			if d := cmp.Diff(tc.expFileStat, got[fileName]); d != "" {
+				t.Errorf("FileStat Differs (-want vs +got): %s\n", d)
+			}
+		})
+	}
+}
+
+func TestFastCountPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unable to create pipe, err: %s", err)
+	}
+	go func() {
+		_, _ = w.Write([]byte("one\ntwo\n"))
+		w.Close()
+	}()
+	defer r.Close()
+
+	// The size of a pipe is not known from stat, so it is read
+	fileName := "/dev/fd/" + strconv.Itoa(int(r.Fd()))
+	got := ProcessFiles([]string{fileName}, ProcessOptions{Fast: FastCountBytes})
+	if d := cmp.Diff(FileStat{"lines": 2, "bytes": 8}, got[fileName]); d != "" {
+		t.Errorf("FileStat Differs (-want vs +got): %s\n", d)
+	}
+}
+
+// benchmarkFile creates a file of about 32 MiB from the gutenberg test text
+func benchmarkFile(b *testing.B) string {
+	b.Helper()
+	text, err := os.ReadFile("../tests/testdata/test.txt")
+	if err != nil {
+		b.Fatalf("Unable to read test text, err: %s", err)
+	}
+	fileName := filepath.Join(b.TempDir(), "bench.txt")
+	if err := os.WriteFile(fileName, bytes.Repeat(text, 100), fs.FileMode(0o600)); err != nil {
+		b.Fatalf("Unable to write %s, err: %s", fileName, err)
+	}
+	return fileName
+}
+
+func benchmarkProcessFiles(b *testing.B, processOptions ProcessOptions) {
+	fileName := benchmarkFile(b)
+	info, err := os.Stat(fileName)
+	if err != nil {
+		b.Fatalf("Unable to stat %s, err: %s", fileName, err)
+	}
+	b.SetBytes(info.Size())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if stats := ProcessFiles([]string{fileName}, processOptions); stats == nil {
+			b.Fatalf("Unable to process %s", fileName)
+		}
+	}
+}
+
+func BenchmarkFullCount(b *testing.B) {
+	benchmarkProcessFiles(b, ProcessOptions{})
+}
+
+func BenchmarkFastCountLines(b *testing.B) {
+	benchmarkProcessFiles(b, ProcessOptions{Fast: FastCountLines})
+}
+
+func BenchmarkFastCountBytes(b *testing.B) {
+	benchmarkProcessFiles(b, ProcessOptions{Fast: FastCountBytes})
+}
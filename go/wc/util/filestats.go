@@ -2,37 +2,91 @@ package util
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+	"github.com/enncod3/coding-challenges/cut/rangeutil"
 )
 
 type FileStat map[string]int
 type FileStats map[string]FileStat
 
-func ProcessFiles(fileNames []string) FileStats {
+type ProcessOptions struct {
+	Encoding charset.Encoding
+	// Restrict the counting to these line or byte ranges, at most one is set
+	LinesRange []rangeutil.Range
+	BytesRange []rangeutil.Range
+	// Count the lines, and the matches if enabled, of each pattern
+	Patterns     []*regexp.Regexp
+	CountMatches bool
+	// Only the lines and/or bytes are needed, ignored with ranges or patterns
+	Fast FastCount
+	// Map the regular files into memory instead of reading them
+	Mmap bool
+}
+
+func (processOptions ProcessOptions) useFastCount() bool {
+	return processOptions.Fast != FastCountNone &&
+		processOptions.LinesRange == nil && processOptions.BytesRange == nil &&
+		len(processOptions.Patterns) == 0
+}
+
+// MatchingLinesKey is the FileStat key for the lines matching the i-th pattern
+func MatchingLinesKey(i int) string {
+	return fmt.Sprintf("matching-lines-%d", i)
+}
+
+// MatchesKey is the FileStat key for the total matches of the i-th pattern
+func MatchesKey(i int) string {
+	return fmt.Sprintf("matches-%d", i)
+}
+
+func ProcessFiles(fileNames []string, processOptions ProcessOptions) FileStats {
 
 	stats := FileStats{}
 
 	// Process the file content
 	for _, fileName := range fileNames {
 
-		data, err := os.ReadFile(fileName)
+		if processOptions.useFastCount() {
+			stat, err := fastCountFile(fileName, processOptions)
+			if err != nil {
+				return nil
+			}
+			stats[fileName] = stat
+			continue
+		}
+
+		data, release, err := readFile(fileName, processOptions.Mmap)
 		if err != nil {
 			return nil
 		}
-		bytesLen := len(data)
-		charLen := utf8.RuneCount(data)
 
-		lineLen := len(strings.Split(string(data), "\n")) - 1
-		wordsLen := len(strings.Fields(string(data)))
+		// Resolve the encoding before selecting, a range may leave out the BOM
+		encoding := processOptions.Encoding
+		if encoding == charset.Auto {
+			encoding, _ = charset.Detect(data)
+		}
+
+		regions := [][]byte{data}
+		switch {
+		case processOptions.LinesRange != nil:
+			regions = SelectLines(data, processOptions.LinesRange, encoding)
+		case processOptions.BytesRange != nil:
+			regions = SelectBytes(data, processOptions.BytesRange)
+		}
 
-		stat := FileStat{
-			"bytes": bytesLen,
-			"chars": charLen,
-			"lines": lineLen,
-			"words": wordsLen,
+		stat := FileStat{"bytes": 0, "chars": 0, "lines": 0, "words": 0}
+		for _, region := range regions {
+			for key, value := range countData(region, encoding, processOptions) {
+				stat[key] += value
+			}
 		}
+		release()
 
 		stats[fileName] = stat
 
@@ -41,9 +95,9 @@ func ProcessFiles(fileNames []string) FileStats {
 	if len(stats) > 1 {
 		totals := FileStat{}
 		for _, filestats := range stats {
-			totals["lines"] += filestats["lines"]
-			totals["words"] += filestats["words"]
-			totals["bytes"] += filestats["bytes"]
+			for key, value := range filestats {
+				totals[key] += value
+			}
 		}
 		stats["totals"] = totals
 	}
@@ -51,40 +105,108 @@ func ProcessFiles(fileNames []string) FileStats {
 
 }
 
+func countData(data []byte, encoding charset.Encoding, processOptions ProcessOptions) FileStat {
+	// The bytes are the on-disk size, the rest are counted on the decoded text
+	bytesLen := len(data)
+	data = DecodeToUTF8(data, encoding)
+	charLen := utf8.RuneCount(data)
+
+	lines := strings.Split(string(data), "\n")
+	lineLen := len(lines) - 1
+	wordsLen := len(strings.Fields(string(data)))
+
+	stat := FileStat{
+		"bytes": bytesLen,
+		"chars": charLen,
+		"lines": lineLen,
+		"words": wordsLen,
+	}
+
+	// The patterns are matched on the lines already split for the line count
+	for i, pattern := range processOptions.Patterns {
+		stat[MatchingLinesKey(i)] = 0
+		if processOptions.CountMatches {
+			stat[MatchesKey(i)] = 0
+		}
+		for j, line := range lines {
+			// The text after the last LF is only a line when it is not empty
+			if j == lineLen && line == "" {
+				continue
+			}
+			if processOptions.CountMatches {
+				matches := len(pattern.FindAllStringIndex(line, -1))
+				stat[MatchesKey(i)] += matches
+				if matches > 0 {
+					stat[MatchingLinesKey(i)]++
+				}
+			} else if pattern.MatchString(line) {
+				stat[MatchingLinesKey(i)]++
+			}
+		}
+	}
+
+	return stat
+}
+
 type PrintOptions struct {
 	Lines bool
 	Words bool
 	Bytes bool
 	Chars bool
+	// Number of patterns whose matching lines, and matches if enabled, are printed
+	Patterns int
+	Matches  bool
 }
 
-// TODO: Refactor this for repetition
-func PrintStats(stats FileStats, printOptions PrintOptions) {
+// columns returns the FileStat keys to print, the lines, words and bytes by
+// default or else the single count chosen by priority, followed by the pattern
+// counts
+func (printOptions PrintOptions) columns() []string {
+	columns := make([]string, 0, 3+2*printOptions.Patterns)
+	switch {
+	case printOptions.Bytes:
+		columns = append(columns, "bytes")
+	case printOptions.Words:
+		columns = append(columns, "words")
+	case printOptions.Chars:
+		columns = append(columns, "chars")
+	case printOptions.Lines:
+		columns = append(columns, "lines")
+	default:
+		columns = append(columns, "lines", "words", "bytes")
+	}
 
-	if !printOptions.Lines && !printOptions.Words && !printOptions.Bytes && !printOptions.Chars {
-		for fileName, stats := range stats {
-			fmt.Printf(" %d\t%d\t%d\t%s\n", stats["lines"], stats["words"], stats["bytes"], fileName)
+	for i := 0; i < printOptions.Patterns; i++ {
+		columns = append(columns, MatchingLinesKey(i))
+		if printOptions.Matches {
+			columns = append(columns, MatchesKey(i))
 		}
-		return
-
 	}
+	return columns
+}
 
-	if printOptions.Bytes {
-		for fileName, stats := range stats {
-			fmt.Printf(" %d\t%s\n", stats["bytes"], fileName)
-		}
-	} else if printOptions.Words {
-		for fileName, stats := range stats {
-			fmt.Printf(" %d\t%s\n", stats["words"], fileName)
-		}
-	} else if printOptions.Chars {
-		for fileName, stats := range stats {
-			fmt.Printf(" %d\t%s\n", stats["chars"], fileName)
-		}
-	} else if printOptions.Lines {
-		for fileName, stats := range stats {
-			fmt.Printf(" %d\t%s\n", stats["lines"], fileName)
+func PrintStats(stats FileStats, printOptions PrintOptions) {
+	FprintStats(os.Stdout, stats, printOptions)
+}
+
+// FprintStats writes a row per file with the selected columns, the totals are
+// written last
+func FprintStats(w io.Writer, stats FileStats, printOptions PrintOptions) {
+	columns := printOptions.columns()
+	printRow := func(fileName string, stat FileStat) {
+		fmt.Fprint(w, " ")
+		for _, column := range columns {
+			fmt.Fprintf(w, "%d\t", stat[column])
 		}
+		fmt.Fprintf(w, "%s\n", fileName)
 	}
 
+	for fileName, stat := range stats {
+		if fileName != "totals" {
+			printRow(fileName, stat)
+		}
+	}
+	if totals, ok := stats["totals"]; ok {
+		printRow("totals", totals)
+	}
 }
@@ -3,6 +3,8 @@ package util
 import (
 	"io/fs"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -93,7 +95,7 @@ Gustavberg`,
 				t.Fatalf("Unable to write %s, err: %s", fp.Name(), err)
 			}
 			// Actual test
-			got := ProcessFiles([]string{fp.Name()})
+			got := ProcessFiles([]string{fp.Name()}, ProcessOptions{})
 			if d := cmp.Diff(tc.expFileStat, got[fp.Name()]); d != "" {
 				t.Errorf("FileStat Differs (-want vs +got): %s\n", d)
 			}
@@ -101,3 +103,92 @@ Gustavberg`,
 	}
 
 }
+
+func TestProcessFilePatterns(t *testing.T) {
+	testcases := map[string]struct {
+		inputFileData string
+		patterns      []string
+		countMatches  bool
+		expFileStat   FileStat
+	}{
+		"MatchingLines": {
+			"one two\nthree\ntwo two\n",
+			[]string{"two"},
+			false,
+			FileStat{"words": 5, "lines": 3, "bytes": 22, "chars": 22,
+				"matching-lines-0": 2},
+		},
+		"MatchingLinesAndMatches": {
+			"one two\nthree\ntwo two\n",
+			[]string{"two", "^t"},
+			true,
+			FileStat{"words": 5, "lines": 3, "bytes": 22, "chars": 22,
+				"matching-lines-0": 2, "matches-0": 3,
+				"matching-lines-1": 2, "matches-1": 2},
+		},
+		"LastLineWithoutLF": {
+			"one\ntwo",
+			[]string{"o"},
+			false,
+			FileStat{"words": 2, "lines": 1, "bytes": 7, "chars": 7,
+				"matching-lines-0": 2},
+		},
+		"EmptyLinesNotPastLastLF": {
+			"one\n\n",
+			[]string{"^$"},
+			false,
+			FileStat{"words": 1, "lines": 2, "bytes": 5, "chars": 5,
+				"matching-lines-0": 1},
+		},
+	}
+
+	tmpDir, err := os.MkdirTemp("/tmp/", "wc-go*")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory, err: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			fp, err := os.CreateTemp(tmpDir, "*.txt")
+			if err != nil {
+				t.Fatalf("Unable to create tempfile err: %s", err)
+			}
+			if err := os.WriteFile(fp.Name(), []byte(tc.inputFileData), fs.FileMode(os.O_WRONLY)); err != nil {
+				t.Fatalf("Unable to write %s, err: %s", fp.Name(), err)
+			}
+			patterns := make([]*regexp.Regexp, 0, len(tc.patterns))
+			for _, expr := range tc.patterns {
+				patterns = append(patterns, regexp.MustCompile(expr))
+			}
+			got := ProcessFiles([]string{fp.Name()}, ProcessOptions{Patterns: patterns, CountMatches: tc.countMatches})
+			if d := cmp.Diff(tc.expFileStat, got[fp.Name()]); d != "" {
+				t.Errorf("FileStat Differs (-want vs +got): %s\n", d)
+			}
+		})
+	}
+}
+
+func TestPrintStats(t *testing.T) {
+	stat := FileStat{"lines": 1, "words": 2, "chars": 3, "bytes": 4, "matching-lines-0": 5, "matches-0": 6}
+	testcases := map[string]struct {
+		printOptions PrintOptions
+		expected     string
+	}{
+		"Default":        {PrintOptions{}, " 1\t2\t4\tfile\n"},
+		"SingleColumn":   {PrintOptions{Chars: true}, " 3\tfile\n"},
+		"ColumnPriority": {PrintOptions{Bytes: true, Chars: true, Words: true, Lines: true}, " 4\tfile\n"},
+		"Patterns":       {PrintOptions{Lines: true, Patterns: 1}, " 1\t5\tfile\n"},
+		"PatternMatches": {PrintOptions{Patterns: 1, Matches: true}, " 1\t2\t4\t5\t6\tfile\n"},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var out strings.Builder
+			FprintStats(&out, FileStats{"file": stat}, tc.printOptions)
+			if d := cmp.Diff(tc.expected, out.String()); d != "" {
+				t.Errorf("Output differs (-want vs +got): %s\n", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+var errMmapUnsupported = errors.New("mmap is not supported for the file")
+
+// readFile returns the content of the file and a function to release it. With
+// mmap a regular file is mapped into memory, which must not be modified while
+// mapped, the pipes and special files are read as usual.
+func readFile(fileName string, mmap bool) ([]byte, func(), error) {
+	if !mmap {
+		data, err := os.ReadFile(fileName)
+		return data, func() {}, err
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	// The mapping stays valid after the file is closed
+	if data, err := mmapFile(f); err == nil {
+		return data, func() { _ = munmapFile(data) }, nil
+	}
+
+	data, err := io.ReadAll(f)
+	return data, func() {}, err
+}
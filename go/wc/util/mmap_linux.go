@@ -0,0 +1,31 @@
+//go:build linux
+
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the whole of a regular file read only
+func mmapFile(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, errMmapUnsupported
+	}
+	// An empty mapping is invalid, there is nothing to map anyway
+	if info.Size() == 0 {
+		return []byte{}, nil
+	}
+	return syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func munmapFile(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package util
+
+import "os"
+
+func mmapFile(f *os.File) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+func munmapFile(data []byte) error {
+	return nil
+}
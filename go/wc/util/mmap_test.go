@@ -0,0 +1,57 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMmapMatchesRead(t *testing.T) {
+	fileNames, err := filepath.Glob("../tests/testdata/*.txt")
+	if err != nil || len(fileNames) == 0 {
+		t.Fatalf("Unable to find the testdata, err: %v", err)
+	}
+
+	for _, processOptions := range []ProcessOptions{
+		{},
+		{Fast: FastCountLines},
+		{Encoding: charset.Latin1},
+	} {
+		want := ProcessFiles(fileNames, processOptions)
+		processOptions.Mmap = true
+		got := ProcessFiles(fileNames, processOptions)
+		if d := cmp.Diff(want, got); d != "" {
+			t.Errorf("FileStats with mmap differ (-want vs +got): %s\n", d)
+		}
+	}
+}
+
+func TestMmapFallsBackForPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unable to create pipe, err: %s", err)
+	}
+	go func() {
+		_, _ = w.Write([]byte("one two\nthree\n"))
+		w.Close()
+	}()
+	defer r.Close()
+
+	fileName := "/dev/fd/" + strconv.Itoa(int(r.Fd()))
+	got := ProcessFiles([]string{fileName}, ProcessOptions{Mmap: true})
+	if d := cmp.Diff(FileStat{"lines": 2, "words": 3, "bytes": 14, "chars": 14}, got[fileName]); d != "" {
+		t.Errorf("FileStat Differs (-want vs +got): %s\n", d)
+	}
+}
+
+func BenchmarkMmapFullCount(b *testing.B) {
+	benchmarkProcessFiles(b, ProcessOptions{Mmap: true})
+}
+
+func BenchmarkMmapFastCountLines(b *testing.B) {
+	benchmarkProcessFiles(b, ProcessOptions{Fast: FastCountLines, Mmap: true})
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"bytes"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+	"github.com/enncod3/coding-challenges/cut/rangeutil"
+)
+
+// SelectBytes returns the regions of data within the 1-based byte ranges, the
+// ranges are expected to be sorted and merged as done by rangeutil.ParseRangeList
+func SelectBytes(data []byte, ranges []rangeutil.Range) [][]byte {
+	regions := make([][]byte, 0, len(ranges))
+	for _, r := range ranges {
+		// A range past the end of data or decreasing selects nothing
+		start, end := min(r.Start-1, len(data)), min(r.End, len(data))
+		if start >= end {
+			continue
+		}
+		regions = append(regions, data[start:end])
+	}
+	return regions
+}
+
+// SelectLines returns the regions of data within the 1-based line ranges, each
+// selected line keeps its terminator. The newline is matched per code unit of
+// the encoding so that UTF-16 input is split on whole characters.
+func SelectLines(data []byte, ranges []rangeutil.Range, enc charset.Encoding) [][]byte {
+	newline, unit := []byte{'\n'}, 1
+	switch enc {
+	case charset.UTF16LE:
+		newline, unit = []byte{'\n', 0}, 2
+	case charset.UTF16BE:
+		newline, unit = []byte{0, '\n'}, 2
+	}
+
+	regions := make([][]byte, 0, len(ranges))
+	lineNumber, lineStart, regionStart := 1, 0, -1
+	for _, r := range ranges {
+		for lineStart < len(data) && lineNumber <= r.End {
+			lineEnd := indexUnit(data, lineStart, newline, unit)
+			if lineNumber == r.Start {
+				regionStart = lineStart
+			}
+			lineStart = lineEnd
+			lineNumber++
+		}
+		if regionStart >= 0 {
+			regions = append(regions, data[regionStart:lineStart])
+			regionStart = -1
+		}
+		if lineStart >= len(data) {
+			break
+		}
+	}
+	return regions
+}
+
+// indexUnit returns the offset just after the next newline aligned to the code
+// unit size starting at from, or the length of data if there is none
+func indexUnit(data []byte, from int, newline []byte, unit int) int {
+	for i := from; i < len(data); {
+		n := bytes.Index(data[i:], newline)
+		if n < 0 {
+			break
+		}
+		if (i+n-from)%unit == 0 {
+			return i + n + len(newline)
+		}
+		i += n + 1
+	}
+	return len(data)
+}
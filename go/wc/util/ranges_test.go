@@ -0,0 +1,93 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/ennc0d3/coding-challenges/wc/charset"
+	"github.com/enncod3/coding-challenges/cut/rangeutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectBytes(t *testing.T) {
+	testcases := map[string]struct {
+		input    string
+		ranges   string
+		expected []string
+	}{
+		"SingleRange":      {"Gutenberg", "1-3", []string{"Gut"}},
+		"MultipleRanges":   {"Gutenberg", "1-3,7-", []string{"Gut", "erg"}},
+		"RangePastEnd":     {"Gut", "2-10", []string{"ut"}},
+		"RangeBeyondData":  {"Gut", "1,5-", []string{"G"}},
+		"RangeJustPastEnd": {"Gut", "4-5", []string{}},
+		"ReversedRange":    {"Gutenberg", "5-3", []string{}},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ranges, err := rangeutil.ParseRangeList(tc.ranges)
+			if err != nil {
+				t.Fatalf("Unable to parse range %s, err: %s", tc.ranges, err)
+			}
+			got := toStrings(SelectBytes([]byte(tc.input), ranges))
+			if d := cmp.Diff(tc.expected, got); d != "" {
+				t.Errorf("Selected bytes differ (-want vs +got): %s\n", d)
+			}
+		})
+	}
+}
+
+func TestSelectLines(t *testing.T) {
+	testcases := map[string]struct {
+		input    string
+		encoding charset.Encoding
+		ranges   string
+		expected []string
+	}{
+		"HeaderLine": {
+			"f1,f2\n1,2\n3,4\n", charset.UTF8, "1",
+			[]string{"f1,f2\n"},
+		},
+		"OpenEndedRange": {
+			"f1,f2\n1,2\n3,4\n", charset.UTF8, "1,3-",
+			[]string{"f1,f2\n", "3,4\n"},
+		},
+		"LastLineWithoutLF": {
+			"one\ntwo", charset.UTF8, "2",
+			[]string{"two"},
+		},
+		"RangeBeyondData": {
+			"one\ntwo\n", charset.UTF8, "5-",
+			[]string{},
+		},
+		"UTF16LEUnalignedNewlineByte": {
+			// U+0A0A contains newline bytes but is not a newline
+			"\xFF\xFEa\x00\x0A\x0A\n\x00b\x00\n\x00", charset.UTF16LE, "2",
+			[]string{"b\x00\n\x00"},
+		},
+		"UTF16BE": {
+			"\xFE\xFF\x00a\x00\n\x00b\x00\n", charset.UTF16BE, "1",
+			[]string{"\xFE\xFF\x00a\x00\n"},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ranges, err := rangeutil.ParseRangeList(tc.ranges)
+			if err != nil {
+				t.Fatalf("Unable to parse range %s, err: %s", tc.ranges, err)
+			}
+			got := toStrings(SelectLines([]byte(tc.input), ranges, tc.encoding))
+			if d := cmp.Diff(tc.expected, got); d != "" {
+				t.Errorf("Selected lines differ (-want vs +got): %s\n", d)
+			}
+		})
+	}
+}
+
+func toStrings(regions [][]byte) []string {
+	strs := make([]string, 0, len(regions))
+	for _, region := range regions {
+		strs = append(strs, string(region))
+	}
+	return strs
+}